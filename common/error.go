@@ -1,6 +1,9 @@
 package common
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrLimitsExceeded is returned when the decoder has reached the maximum
 // number of allowed resources to parse.
@@ -8,3 +11,109 @@ var ErrLimitsExceeded = errors.New("safety limits exceeded")
 
 // ErrInvalid is returned when an invalid file construct is detected.
 var ErrInvalid = errors.New("invalid construct")
+
+// ErrStop can be returned by an EventHandler in order to stop
+// scanning early, without the Scanner returning an error.
+//
+// This is useful when only the beginning of a resource is of
+// interest (e.g. only the material library declarations).
+var ErrStop = errors.New("stop scanning")
+
+// ParseError is returned when a Wavefront resource could not be
+// scanned. It indicates where in the resource the problem was
+// detected.
+//
+// The actual cause can be inspected through errors.Is and errors.As,
+// since ParseError wraps it (e.g. errors.Is(err, ErrInvalid)).
+type ParseError struct {
+
+	// Line holds the number of the physical line, starting from 1,
+	// where the problem was detected.
+	Line int
+
+	// Column holds the byte offset within the physical line,
+	// starting from 1, where the problem was detected.
+	Column int
+
+	// Command holds the name of the command that could not be
+	// parsed. It is empty if the problem is not related to
+	// a specific command.
+	Command string
+
+	// Err holds the cause of the problem.
+	Err error
+}
+
+// NewParseError creates a new ParseError that points to the command
+// of the specified logical line.
+func NewParseError(line Line, err error) *ParseError {
+	return newParseError(line, line.Position(), err)
+}
+
+// NewParamParseError creates a new ParseError that points to the
+// parameter at the specified index of the specified logical line.
+func NewParamParseError(line Line, index int, err error) *ParseError {
+	return newParseError(line, line.ParamPosition(index), err)
+}
+
+func newParseError(line Line, position Position, err error) *ParseError {
+	var command string
+	if line.IsCommand() {
+		command = line.CommandName()
+	}
+	return &ParseError{
+		Line:    position.Line,
+		Column:  position.Column,
+		Command: command,
+		Err:     err,
+	}
+}
+
+// Error returns a textual representation of this error.
+func (e *ParseError) Error() string {
+	if e.Command == "" {
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %v", e.Line, e.Column, e.Command, e.Err)
+}
+
+// Unwrap returns the cause of this error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Warning describes a problem within a Wavefront resource that
+// did not stop its processing (e.g. a malformed line that was
+// skipped while scanning in ScanModeLenient).
+//
+// A *ParseError can be converted to a Warning.
+type Warning struct {
+
+	// Line holds the number of the physical line, starting from 1,
+	// where the problem was detected.
+	Line int
+
+	// Column holds the byte offset within the physical line,
+	// starting from 1, where the problem was detected.
+	Column int
+
+	// Command holds the name of the command that could not be
+	// processed. It is empty if the command is not known.
+	Command string
+
+	// Err holds the cause of the problem.
+	Err error
+}
+
+// String returns a textual representation of this warning.
+func (w Warning) String() string {
+	if w.Command == "" {
+		return fmt.Sprintf("line %d, column %d: %v", w.Line, w.Column, w.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %v", w.Line, w.Column, w.Command, w.Err)
+}
+
+// WarningHandler function can be passed to a Scanner or Decoder by
+// the API user in order to be notified of the problems that have
+// been skipped over.
+type WarningHandler func(warning Warning)
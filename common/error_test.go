@@ -0,0 +1,76 @@
+package common_test
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/go-data-front/common"
+)
+
+var _ = Describe("ParseError", func() {
+	var line common.Line
+
+	BeforeEach(func() {
+		lineScanner := common.NewLineScanner(strings.NewReader("# comment\n  command first second\n"))
+		Expect(lineScanner.Scan()).To(BeTrue())
+		Expect(lineScanner.Scan()).To(BeTrue())
+		line = lineScanner.Line()
+	})
+
+	It("can point to a command", func() {
+		err := common.NewParseError(line, common.ErrInvalid)
+		Expect(err.Line).To(Equal(2))
+		Expect(err.Column).To(Equal(3))
+		Expect(err.Command).To(Equal("command"))
+		Expect(err.Error()).To(Equal("line 2, column 3: command: invalid construct"))
+	})
+
+	It("can point to a parameter", func() {
+		err := common.NewParamParseError(line, 1, common.ErrInvalid)
+		Expect(err.Line).To(Equal(2))
+		Expect(err.Column).To(Equal(17))
+		Expect(err.Command).To(Equal("command"))
+	})
+
+	It("wraps the cause", func() {
+		cause := errors.New("cause")
+		var err error = common.NewParseError(line, cause)
+		Expect(errors.Is(err, cause)).To(BeTrue())
+
+		var parseErr *common.ParseError
+		Expect(errors.As(err, &parseErr)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Warning", func() {
+	var line common.Line
+
+	BeforeEach(func() {
+		lineScanner := common.NewLineScanner(strings.NewReader("  command first second\n"))
+		Expect(lineScanner.Scan()).To(BeTrue())
+		line = lineScanner.Line()
+	})
+
+	It("can be created from a parse error", func() {
+		warning := common.Warning(*common.NewParamParseError(line, 0, common.ErrInvalid))
+		Expect(warning).To(Equal(common.Warning{
+			Line:    1,
+			Column:  11,
+			Command: "command",
+			Err:     common.ErrInvalid,
+		}))
+		Expect(warning.String()).To(Equal("line 1, column 11: command: invalid construct"))
+	})
+
+	It("can be without a command", func() {
+		warning := common.Warning{
+			Line:   3,
+			Column: 1,
+			Err:    common.ErrInvalid,
+		}
+		Expect(warning.String()).To(Equal("line 3, column 1: invalid construct"))
+	})
+})
@@ -3,11 +3,27 @@ package common
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
+// Position describes a location within a Wavefront resource.
+type Position struct {
+
+	// Line holds the number of the physical line, starting from 1.
+	Line int
+
+	// Column holds the byte offset within the physical line,
+	// starting from 1.
+	Column int
+}
+
 // ReferenceSet represents a set of references.
 // This is represented in a Wavefront through a list of
 // values separated by `/` symbols. It is possible to have
@@ -64,7 +80,34 @@ func (s ReferenceSet) FloatReference(index int) (float64, error) {
 type Line struct {
 	// contains filtered or unexported fields
 	line     string
+	text     string
 	segments []string
+	offsets  []int
+	pieces   []linePiece
+	size     int
+}
+
+// linePiece describes a physical line that is part of a logical line.
+type linePiece struct {
+	offset int
+	number int
+}
+
+// Position returns the location of the current logical line within the
+// Wavefront resource. If the line is not blank, the location points to
+// the first non-blank character.
+func (l Line) Position() Position {
+	if len(l.offsets) == 0 {
+		return l.offsetPosition(0)
+	}
+	return l.offsetPosition(l.offsets[0])
+}
+
+// Size returns the number of bytes that the current logical line
+// occupies within the Wavefront resource, including all of its
+// continuation lines and line terminators.
+func (l Line) Size() int {
+	return l.size
 }
 
 // IsBlank returns whether the current logical line is blank
@@ -73,8 +116,27 @@ func (l Line) IsBlank() bool {
 }
 
 // IsComment returns whether the current logical line represents a comment
+//
+// Lines that hold a comment directive are not considered comments, since
+// they carry data (see IsCommentDirective).
 func (l Line) IsComment() bool {
-	return strings.HasPrefix(l.line, "#")
+	return strings.HasPrefix(l.line, "#") && !l.IsCommentDirective()
+}
+
+// IsCommentDirective returns whether the current logical line represents
+// a vendor-specific command that is prefixed with `#` and would otherwise
+// be treated as a comment (e.g. ZBrush `#MRGB` vertex color blocks).
+//
+// Only a fixed set of well-known directives (currently `#MRGB`) is
+// recognized. All other lines that start with `#` are treated as comments.
+func (l Line) IsCommentDirective() bool {
+	return l.IsCommand() && slices.Contains(commentDirectives, l.CommandName())
+}
+
+// commentDirectives lists the names of the vendor-specific commands that
+// are prefixed with `#` and are recognized as commands instead of comments.
+var commentDirectives = []string{
+	"#MRGB",
 }
 
 // Comment returns the comment held by this logical line. One should first
@@ -114,6 +176,44 @@ func (l Line) StringParam(index int) string {
 	return l.segments[index+1]
 }
 
+// Params returns all parameters of the current command as strings.
+func (l Line) Params() []string {
+	return slices.Clone(l.segments[min(1, len(l.segments)):])
+}
+
+// Remainder returns the raw text of the current line, starting from the
+// parameter at the specified index up to the end of the line.
+//
+// Unlike StringParam, this preserves any whitespace between parameters,
+// which makes it possible to read values such as file paths that contain
+// spaces. Trailing whitespace is removed.
+func (l Line) Remainder(index int) string {
+	return strings.TrimRightFunc(l.text[l.offsets[index+1]:], unicode.IsSpace)
+}
+
+// ParamPosition returns the location of the parameter at the specified index
+// within the Wavefront resource.
+func (l Line) ParamPosition(index int) Position {
+	return l.offsetPosition(l.offsets[index+1])
+}
+
+func (l Line) offsetPosition(offset int) Position {
+	if len(l.pieces) == 0 {
+		return Position{}
+	}
+	piece := l.pieces[0]
+	for _, candidate := range l.pieces[1:] {
+		if candidate.offset > offset {
+			break
+		}
+		piece = candidate
+	}
+	return Position{
+		Line:   piece.number,
+		Column: offset - piece.offset + 1,
+	}
+}
+
 // IntParam returns the parameter, converted to an integer, at the specified index
 func (l Line) IntParam(index int) (int64, error) {
 	value, err := strconv.ParseInt(l.StringParam(index), 10, 64)
@@ -161,34 +261,90 @@ type LineScanner interface {
 	Line() Line
 }
 
+// DefaultMaxLineLength is the maximum length in bytes of a logical
+// line that is used by NewLineScanner.
+const DefaultMaxLineLength = 1024 * 1024
+
+// UnlimitedLineLength can be used as a maximum line length in order
+// to allow logical lines of arbitrary length.
+const UnlimitedLineLength = -1
+
 type lineScanner struct {
-	scanner    *bufio.Scanner
-	lineBuffer bytes.Buffer
-	scanLine   Line
-	scanErr    error
+	scanner       *bufio.Scanner
+	maxLineLength int
+	lineBuffer    bytes.Buffer
+	lineNumber    int
+	lineSize      int
+	scanLine      Line
+	scanErr       error
 }
 
 // NewLineScanner creates a new LineScanner instance that uses the
 // specified io.Reader to read a Wavefront resource.
+//
+// Logical lines are limited to DefaultMaxLineLength bytes.
 func NewLineScanner(reader io.Reader) LineScanner {
-	return &lineScanner{
-		scanner:    bufio.NewScanner(reader),
-		lineBuffer: bytes.Buffer{},
+	return NewLimitedLineScanner(reader, DefaultMaxLineLength)
+}
+
+// NewLimitedLineScanner creates a new LineScanner instance that uses the
+// specified io.Reader to read a Wavefront resource.
+//
+// The maxLineLength parameter specifies the maximum length in bytes that
+// a logical line (including all of its continuation lines) can have. If
+// it is exceeded, scanning fails with ErrLimitsExceeded. A value of zero
+// results in DefaultMaxLineLength being used. Use UnlimitedLineLength (or
+// any other negative value) to disable this restriction.
+func NewLimitedLineScanner(reader io.Reader, maxLineLength int) LineScanner {
+	switch {
+	case maxLineLength == 0:
+		maxLineLength = DefaultMaxLineLength
+	case maxLineLength < 0:
+		maxLineLength = math.MaxInt
+	}
+	// The buffer needs to fit the line terminator as well.
+	bufferLength := maxLineLength
+	if bufferLength < math.MaxInt-2 {
+		bufferLength += 2
 	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, min(bufio.MaxScanTokenSize, bufferLength)), bufferLength)
+	result := &lineScanner{
+		scanner:       scanner,
+		maxLineLength: maxLineLength,
+		lineBuffer:    bytes.Buffer{},
+	}
+	scanner.Split(result.splitLines)
+	return result
+}
+
+// splitLines behaves like bufio.ScanLines but additionally keeps
+// track of the number of bytes that the scanned lines occupy.
+func (s *lineScanner) splitLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	s.lineSize += advance
+	return advance, token, err
 }
 
 func (s *lineScanner) Scan() bool {
+	if s.scanErr != nil {
+		return false
+	}
 	s.lineBuffer.Reset()
+	s.lineSize = 0
 
-	scanIterations := 0
+	var pieces []linePiece
 	for s.scanner.Scan() {
-		if s.scanner.Err() != nil {
-			s.scanErr = s.scanner.Err()
+		s.lineNumber++
+		pieces = append(pieces, linePiece{
+			offset: s.lineBuffer.Len(),
+			number: s.lineNumber,
+		})
+		line := s.scanner.Text()
+		if s.lineBuffer.Len()+len(line) > s.maxLineLength {
+			s.scanErr = s.newLineLengthError()
 			return false
 		}
-
-		scanIterations++
-		line := s.scanner.Text()
 		if strings.HasSuffix(line, `\`) {
 			s.lineBuffer.WriteString(strings.TrimSuffix(line, `\`))
 		} else {
@@ -197,14 +353,63 @@ func (s *lineScanner) Scan() bool {
 		}
 	}
 
-	s.scanLine = s.createLine(s.lineBuffer.String())
-	return scanIterations > 0
+	if err := s.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			s.lineNumber++
+			s.scanErr = s.newLineLengthError()
+		} else {
+			s.scanErr = err
+		}
+		return false
+	}
+
+	s.scanLine = s.createLine(s.lineBuffer.String(), pieces)
+	return len(pieces) > 0
 }
 
-func (s *lineScanner) createLine(logicalLine string) Line {
+func (s *lineScanner) createLine(logicalLine string, pieces []linePiece) Line {
+	segments, offsets := splitFields(logicalLine)
 	return Line{
 		line:     strings.TrimSpace(logicalLine),
-		segments: strings.Fields(logicalLine),
+		text:     logicalLine,
+		segments: segments,
+		offsets:  offsets,
+		pieces:   pieces,
+		size:     s.lineSize,
+	}
+}
+
+// splitFields behaves like strings.Fields but additionally returns
+// the byte offset of each field within the specified string.
+func splitFields(value string) ([]string, []int) {
+	var (
+		segments []string
+		offsets  []int
+	)
+	start := -1
+	for i, r := range value {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				segments = append(segments, value[start:i])
+				offsets = append(offsets, start)
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		segments = append(segments, value[start:])
+		offsets = append(offsets, start)
+	}
+	return segments, offsets
+}
+
+func (s *lineScanner) newLineLengthError() error {
+	return &ParseError{
+		Line:   s.lineNumber,
+		Column: 1,
+		Err:    fmt.Errorf("%w: line exceeds %d bytes", ErrLimitsExceeded, s.maxLineLength),
 	}
 }
 
@@ -2,13 +2,16 @@ package common_test
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/mokiat/go-data-front/common"
+	"github.com/mokiat/go-data-front/internal/testutil"
 )
 
 var _ = Describe("LineScanner", func() {
@@ -211,6 +214,13 @@ var _ = Describe("LineScanner", func() {
 			Expect(floatParams.ParamCount()).To(Equal(3))
 		})
 
+		It("can scan all parameters at once", func() {
+			Expect(noParams.Params()).To(BeEmpty())
+			Expect(stringParams.Params()).To(Equal([]string{
+				"hello", "complex/param", "\"quoted\"", "?123?",
+			}))
+		})
+
 		It("can scan string parameters when strings", func() {
 			Expect(stringParams.StringParam(0)).To(Equal("hello"))
 			Expect(stringParams.StringParam(1)).To(Equal("complex/param"))
@@ -374,6 +384,71 @@ var _ = Describe("LineScanner", func() {
 		})
 	})
 
+	Describe("scanning positions", func() {
+		var (
+			first  common.Line
+			blank  common.Line
+			second common.Line
+			third  common.Line
+		)
+
+		BeforeEach(func() {
+			lineScanner = openForScanning("line_scanner_positions.txt")
+			first = readNextLine()
+			blank = readNextLine()
+			second = readNextLine()
+			third = readNextLine()
+			assertNoMoreLines()
+		})
+
+		It("can determine the position of lines", func() {
+			Expect(first.Position()).To(Equal(common.Position{Line: 1, Column: 1}))
+			Expect(blank.Position()).To(Equal(common.Position{Line: 2, Column: 1}))
+			Expect(second.Position()).To(Equal(common.Position{Line: 3, Column: 3}))
+			Expect(third.Position()).To(Equal(common.Position{Line: 5, Column: 1}))
+		})
+
+		It("can determine the position of parameters", func() {
+			Expect(first.ParamPosition(0)).To(Equal(common.Position{Line: 1, Column: 7}))
+			Expect(first.ParamPosition(1)).To(Equal(common.Position{Line: 1, Column: 9}))
+		})
+
+		It("can determine the position of parameters on continuation lines", func() {
+			assertCommandParams(second, "c", "d")
+			Expect(second.ParamPosition(0)).To(Equal(common.Position{Line: 3, Column: 11}))
+			Expect(second.ParamPosition(1)).To(Equal(common.Position{Line: 4, Column: 4}))
+		})
+	})
+
+	Describe("scanning remainders", func() {
+		var (
+			first  common.Line
+			second common.Line
+			third  common.Line
+		)
+
+		BeforeEach(func() {
+			lineScanner = openForScanning("line_scanner_remainder.txt")
+			first = readNextLine()
+			second = readNextLine()
+			third = readNextLine()
+			assertNoMoreLines()
+		})
+
+		It("can return the remainder with inner whitespace", func() {
+			Expect(first.Remainder(2)).To(Equal("My Textures/brick  wall.png"))
+			Expect(first.Remainder(0)).To(Equal("-clamp on My Textures/brick  wall.png"))
+		})
+
+		It("can return the remainder of the last parameter", func() {
+			Expect(second.Remainder(0)).To(Equal("single.mtl"))
+		})
+
+		It("can return the remainder across continuation lines", func() {
+			Expect(third.Remainder(0)).To(Equal("split   across lines.mtl"))
+		})
+	})
+
 	Describe("scanning logical lines", func() {
 		var (
 			first  common.Line
@@ -400,4 +475,182 @@ var _ = Describe("LineScanner", func() {
 			assertCommandParams(third, "ends", "with", "separation")
 		})
 	})
+
+	When("scanning lines with different terminators", func() {
+		It("reports the number of bytes that each line occupies", func() {
+			lineScanner = common.NewLineScanner(strings.NewReader("v 1 \\\n2 3\r\n\nvn 0 1 0"))
+			Expect(readNextLine().Size()).To(Equal(11))
+			Expect(readNextLine().Size()).To(Equal(1))
+			Expect(readNextLine().Size()).To(Equal(8))
+			assertNoMoreLines()
+		})
+	})
+
+	When("scanning comment directives", func() {
+		var (
+			directive common.Line
+			comment   common.Line
+		)
+
+		BeforeEach(func() {
+			lineScanner = common.NewLineScanner(strings.NewReader("#MRGB ff112233 ff445566\n#MRGBA is not a directive\n"))
+			directive = readNextLine()
+			comment = readNextLine()
+			assertNoMoreLines()
+		})
+
+		It("treats known directives as commands", func() {
+			assertIsNotComment(directive)
+			Expect(directive.IsCommentDirective()).To(BeTrue())
+			assertIsCommand(directive, "#MRGB")
+			assertCommandParams(directive, "ff112233", "ff445566")
+		})
+
+		It("treats other lines that start with a hash as comments", func() {
+			Expect(comment.IsCommentDirective()).To(BeFalse())
+			assertIsComment(comment, "MRGBA is not a directive")
+		})
+	})
+})
+
+var _ = Describe("LineScanner limits", func() {
+	var (
+		lineScanner common.LineScanner
+		content     string
+	)
+
+	scanAll := func() (int, error) {
+		count := 0
+		for lineScanner.Scan() {
+			count++
+		}
+		return count, lineScanner.Err()
+	}
+
+	When("a physical line is longer than the bufio default", func() {
+		BeforeEach(func() {
+			content = "f" + strings.Repeat(" 1/1/1", 20000) + "\nv 1 2 3\n"
+			lineScanner = common.NewLineScanner(strings.NewReader(content))
+		})
+
+		It("scans it successfully", func() {
+			Expect(lineScanner.Scan()).To(BeTrue())
+			Expect(lineScanner.Line().ParamCount()).To(Equal(20000))
+			Expect(lineScanner.Scan()).To(BeTrue())
+			Expect(lineScanner.Line().CommandName()).To(Equal("v"))
+			Expect(lineScanner.Scan()).To(BeFalse())
+			Expect(lineScanner.Err()).ToNot(HaveOccurred())
+		})
+	})
+
+	When("a physical line exceeds the limit", func() {
+		BeforeEach(func() {
+			content = "v 1 2 3\n" + "f" + strings.Repeat(" 1/1/1", 100) + "\n"
+			lineScanner = common.NewLimitedLineScanner(strings.NewReader(content), 128)
+		})
+
+		It("returns a limits exceeded error", func() {
+			count, err := scanAll()
+			Expect(count).To(Equal(1))
+			Expect(errors.Is(err, common.ErrLimitsExceeded)).To(BeTrue())
+
+			var parseErr *common.ParseError
+			Expect(errors.As(err, &parseErr)).To(BeTrue())
+			Expect(parseErr.Line).To(Equal(2))
+		})
+	})
+
+	When("a logical line exceeds the limit", func() {
+		BeforeEach(func() {
+			content = strings.Repeat("f 1/1/1 2/2/2 3/3/3 \\\n", 10) + "\n"
+			lineScanner = common.NewLimitedLineScanner(strings.NewReader(content), 128)
+		})
+
+		It("returns a limits exceeded error", func() {
+			count, err := scanAll()
+			Expect(count).To(Equal(0))
+			Expect(errors.Is(err, common.ErrLimitsExceeded)).To(BeTrue())
+		})
+	})
+
+	When("the line length limit is zero", func() {
+		BeforeEach(func() {
+			content = "#" + strings.Repeat(" ", common.DefaultMaxLineLength) + "\n"
+			lineScanner = common.NewLimitedLineScanner(strings.NewReader(content), 0)
+		})
+
+		It("applies the default limit", func() {
+			count, err := scanAll()
+			Expect(count).To(Equal(0))
+			Expect(errors.Is(err, common.ErrLimitsExceeded)).To(BeTrue())
+		})
+	})
+
+	When("the line length is unlimited", func() {
+		BeforeEach(func() {
+			content = "f" + strings.Repeat(" 1/1/1", 200000) + "\n"
+			lineScanner = common.NewLimitedLineScanner(strings.NewReader(content), common.UnlimitedLineLength)
+		})
+
+		It("scans arbitrarily long lines", func() {
+			count, err := scanAll()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+	})
+
+	When("the reader fails", func() {
+		var errStubbed = errors.New("stubbed to fail")
+
+		BeforeEach(func() {
+			lineScanner = common.NewLineScanner(testutil.NewFailingReader(errStubbed))
+		})
+
+		It("returns the reader error", func() {
+			_, err := scanAll()
+			Expect(err).To(Equal(errStubbed))
+		})
+	})
+})
+
+var _ = Describe("ScanOptions", func() {
+	var options common.ScanOptions
+
+	Describe("DefaultScanOptions", func() {
+		BeforeEach(func() {
+			options = common.DefaultScanOptions()
+		})
+
+		Specify("line length limit should be the default one", func() {
+			Expect(options.MaxLineLength).To(Equal(common.DefaultMaxLineLength))
+		})
+
+		Specify("material libraries should be treated as a list", func() {
+			Expect(options.SingleMaterialLibraryPath).To(BeFalse())
+		})
+
+		Specify("mode should be the standard one", func() {
+			Expect(options.Mode).To(Equal(common.ScanModeStandard))
+		})
+
+		Specify("there should be no warning handler", func() {
+			Expect(options.WarningHandler).To(BeNil())
+		})
+
+		Specify("context check interval should be the default one", func() {
+			Expect(options.ContextCheckInterval).To(Equal(common.DefaultContextCheckInterval))
+		})
+
+		Specify("there should be no progress handler", func() {
+			Expect(options.ProgressHandler).To(BeNil())
+		})
+
+		Specify("progress interval should be the default one", func() {
+			Expect(options.ProgressInterval).To(Equal(common.DefaultProgressInterval))
+		})
+
+		Specify("total size should be unknown", func() {
+			Expect(options.TotalBytes).To(BeZero())
+		})
+	})
 })
@@ -1,6 +1,10 @@
 package common
 
-import "io"
+import (
+	"context"
+	"io"
+	"time"
+)
 
 // Event represents an event that has occurred during scanning.
 // This is the mechanism through which the Scanner API returns
@@ -23,6 +27,20 @@ type CommentEvent struct {
 	Comment string
 }
 
+// UnknownCommandEvent indicates that a command that is not
+// supported by the Scanner has been scanned through.
+//
+// This makes it possible to preserve or report vendor-specific
+// extensions and typos, instead of silently dropping them.
+type UnknownCommandEvent struct {
+
+	// CommandName holds the name of the unsupported command.
+	CommandName string
+
+	// Params holds the raw parameters of the command.
+	Params []string
+}
+
 // EventHandler function is passed to the Scanner by the API user
 // in order to receive scanning events.
 //
@@ -31,14 +49,156 @@ type CommentEvent struct {
 // data from it.
 //
 // Implementations can return an error in order to stop any further
-// processing of the Wavefront file.
+// processing of the Wavefront file. Returning ErrStop stops the
+// processing without the Scanner returning an error.
 type EventHandler func(event Event) error
 
+// EventSource describes the element of a Wavefront resource that
+// triggered an event.
+type EventSource struct {
+
+	// Position holds the location of the element within the
+	// Wavefront resource.
+	Position
+
+	// Command holds the name of the command that contains the
+	// element. It is empty for comments.
+	Command string
+}
+
+// PositionedEventHandler is like EventHandler but additionally
+// receives the EventSource of the element that triggered the event.
+type PositionedEventHandler func(event Event, source EventSource) error
+
+// Progress describes how far a Scanner has gotten through a
+// Wavefront resource.
+type Progress struct {
+
+	// BytesRead holds the number of bytes of the resource that
+	// have been processed so far.
+	BytesRead int64
+
+	// TotalBytes holds the size of the resource in bytes. It is
+	// zero if the size is not known.
+	TotalBytes int64
+
+	// Lines holds the number of logical lines that have been
+	// processed so far.
+	Lines int
+
+	// Elements holds the number of elements (i.e. commands) that
+	// have been processed so far.
+	Elements int
+}
+
+// ProgressHandler function can be passed to a Scanner or Decoder
+// by the API user in order to be notified of the scanning progress.
+type ProgressHandler func(progress Progress)
+
+// DefaultProgressInterval is the minimum amount of time between
+// two progress reports when using the default ScanOptions.
+const DefaultProgressInterval = 100 * time.Millisecond
+
+// DefaultContextCheckInterval is the number of logical lines
+// after which a Scanner checks whether its context.Context has
+// been cancelled, when using the default ScanOptions.
+const DefaultContextCheckInterval = 1024
+
+// ScanMode specifies how a Scanner should react to problems in
+// a Wavefront resource.
+type ScanMode int
+
+const (
+	// ScanModeStandard specifies that malformed lines result in
+	// an error, while unknown commands are reported through a
+	// common.UnknownCommandEvent and excess parameters and
+	// out-of-range values are tolerated.
+	ScanModeStandard ScanMode = iota
+
+	// ScanModeStrict specifies that, in addition to malformed
+	// lines, unknown commands, excess parameters and values that
+	// are outside of the range allowed by the specification
+	// result in an error.
+	ScanModeStrict
+
+	// ScanModeLenient specifies that malformed lines are skipped
+	// and reported through the WarningHandler of the ScanOptions.
+	ScanModeLenient
+)
+
+// ScanOptions specifies how a Scanner should process a Wavefront
+// resource.
+type ScanOptions struct {
+
+	// MaxLineLength specifies the maximum length in bytes that a
+	// logical line (including all of its continuation lines) can
+	// have before an error is returned.
+	//
+	// A value of zero results in DefaultMaxLineLength being used.
+	// Use UnlimitedLineLength to allow lines of arbitrary length.
+	MaxLineLength int
+
+	// SingleMaterialLibraryPath specifies whether the parameters of
+	// a material library declaration (`mtllib`) should be treated as
+	// a single path that may contain spaces, instead of as a list of
+	// paths. This only applies to OBJ resources.
+	//
+	// The OBJ specification allows multiple libraries to be listed in
+	// a single declaration, which is why this is disabled by default.
+	SingleMaterialLibraryPath bool
+
+	// Mode specifies how problems in the resource are handled.
+	Mode ScanMode
+
+	// WarningHandler, if specified, is called for each malformed
+	// line that is skipped in ScanModeLenient.
+	WarningHandler WarningHandler
+
+	// ContextCheckInterval specifies the number of logical lines
+	// after which the context.Context passed to ScanContext is
+	// checked for cancellation. Values lower than 1 result in a
+	// check before every line.
+	ContextCheckInterval int
+
+	// ProgressHandler, if specified, is called periodically
+	// while scanning, as well as once scanning has completed.
+	ProgressHandler ProgressHandler
+
+	// ProgressInterval specifies the minimum amount of time
+	// between two calls to the ProgressHandler. A value of zero
+	// results in DefaultProgressInterval being used, while negative
+	// values result in a call after every line.
+	ProgressInterval time.Duration
+
+	// TotalBytes specifies the size of the resource in bytes, if
+	// it is known. Otherwise, the size is determined when the
+	// io.Reader is an io.Seeker.
+	TotalBytes int64
+}
+
+// DefaultScanOptions returns some default ScanOptions.
+// Users can take the result and modify specific parameters.
+func DefaultScanOptions() ScanOptions {
+	return ScanOptions{
+		MaxLineLength:             DefaultMaxLineLength,
+		SingleMaterialLibraryPath: false,
+		Mode:                      ScanModeStandard,
+		WarningHandler:            nil,
+		ContextCheckInterval:      DefaultContextCheckInterval,
+		ProgressHandler:           nil,
+		ProgressInterval:          DefaultProgressInterval,
+		TotalBytes:                0,
+	}
+}
+
 // Scanner represents an event-based parser for Wavefront resources.
 //
 // Implementations of this interface would usually scan through the
 // Wavefront resource and act on each special element that is
 // detected (e.g. when a normal or material declaration is parsed)
+//
+// A Scanner keeps no state between scans, which makes it safe to
+// use a single Scanner from multiple goroutines at the same time.
 type Scanner interface {
 
 	// Scan performs a scan through the Wavefront resource that is
@@ -48,6 +208,39 @@ type Scanner interface {
 	// pass scanning events back to the user for processing.
 	//
 	// An error is returned should parsing fail for some reason or
-	// if the user returns an error via the EventHandler.
+	// if the user returns an error via the EventHandler. Parsing
+	// problems are reported through a *ParseError. If the user
+	// returns ErrStop, scanning stops and no error is returned.
 	Scan(io.Reader, EventHandler) error
 }
+
+// ContextScanner is a Scanner that can be stopped through a
+// context.Context.
+//
+// The scanners in this module implement this interface, which can
+// be reached through a type assertion on the returned Scanner.
+type ContextScanner interface {
+	Scanner
+
+	// ScanContext is like Scan but stops scanning once the
+	// specified context.Context is done, in which case the error
+	// of the context is returned.
+	//
+	// The context is checked periodically, as specified by the
+	// ContextCheckInterval of the ScanOptions.
+	ScanContext(context.Context, io.Reader, EventHandler) error
+}
+
+// PositionedScanner is a ContextScanner that can additionally
+// report where each event originates from.
+//
+// The scanners in this module implement this interface, which can
+// be reached through a type assertion on the returned Scanner.
+type PositionedScanner interface {
+	ContextScanner
+
+	// ScanPositioned is like ScanContext but additionally passes
+	// the EventSource of the element that triggered each event
+	// to the PositionedEventHandler.
+	ScanPositioned(context.Context, io.Reader, PositionedEventHandler) error
+}
@@ -1,8 +1,11 @@
 package mtl
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/mokiat/go-data-front/common"
 	mtlscan "github.com/mokiat/go-data-front/scanner/mtl"
@@ -18,13 +21,119 @@ type DecodeLimits struct {
 	// MaxMaterialCount specifies the maximum number of
 	// material declarations that can be parsed.
 	MaxMaterialCount int
+
+	// MaxUnknownCommandCount specifies the maximum number of
+	// unsupported commands that are collected. Any further
+	// unsupported commands are dropped.
+	//
+	// A value of zero results in the default limit being used.
+	MaxUnknownCommandCount int
+
+	// MaxLineLength specifies the maximum length in bytes that
+	// a logical line can have before an error is thrown.
+	//
+	// A value of zero results in common.DefaultMaxLineLength being
+	// used. A value of common.UnlimitedLineLength disables this
+	// check.
+	MaxLineLength int
 }
 
 // DefaultLimits returns some default DecodeLimits.
 // Users can take the result and modify specific parameters.
 func DefaultLimits() DecodeLimits {
 	return DecodeLimits{
-		MaxMaterialCount: 512,
+		MaxMaterialCount:       512,
+		MaxUnknownCommandCount: 1024,
+		MaxLineLength:          common.DefaultMaxLineLength,
+	}
+}
+
+// withDefaults returns a copy of the DecodeLimits in which the
+// limits that are zero are replaced by their default values, for
+// those limits where zero is not a meaningful restriction.
+func (l DecodeLimits) withDefaults() DecodeLimits {
+	defaults := DefaultLimits()
+	if l.MaxUnknownCommandCount == 0 {
+		l.MaxUnknownCommandCount = defaults.MaxUnknownCommandCount
+	}
+	return l
+}
+
+// DissolveConvention specifies how the dissolve of a material
+// is determined when it has both a dissolve (`d`) and a
+// transparency (`Tr`) declaration.
+type DissolveConvention int
+
+const (
+	// DissolvePreferDissolve specifies that a transparency
+	// declaration is only used when the material does not have
+	// a dissolve declaration.
+	DissolvePreferDissolve DissolveConvention = iota
+
+	// DissolvePreferTransparency specifies that a dissolve
+	// declaration is only used when the material does not have
+	// a transparency declaration.
+	DissolvePreferTransparency
+
+	// DissolveLastDeclared specifies that the declaration that
+	// appears last in the material is used.
+	DissolveLastDeclared
+)
+
+// DecodeOptions specifies how an MTL resource should be
+// mapped to the Library model.
+type DecodeOptions struct {
+
+	// DissolveConvention specifies which declaration determines
+	// the Dissolve of a material that has both a dissolve and a
+	// transparency declaration. Transparency declarations are
+	// inverted when mapped to Dissolve.
+	DissolveConvention DissolveConvention
+
+	// Mode specifies how problems in the resource are handled.
+	//
+	// In common.ScanModeLenient, malformed lines and invalid
+	// constructs (e.g. a declaration outside of a material)
+	// are skipped and reported through the WarningHandler.
+	Mode common.ScanMode
+
+	// WarningHandler, if specified, is called for each problem
+	// that is skipped in common.ScanModeLenient.
+	WarningHandler common.WarningHandler
+
+	// ContextCheckInterval specifies the number of logical lines
+	// after which the context.Context passed to DecodeContext is
+	// checked for cancellation. Values lower than 1 result in a
+	// check before every line.
+	ContextCheckInterval int
+
+	// ProgressHandler, if specified, is called periodically
+	// while decoding, as well as once decoding has completed.
+	ProgressHandler common.ProgressHandler
+
+	// ProgressInterval specifies the minimum amount of time
+	// between two calls to the ProgressHandler. A value of zero
+	// results in common.DefaultProgressInterval being used,
+	// while negative values result in a call after every line.
+	ProgressInterval time.Duration
+
+	// TotalBytes specifies the size of the resource in bytes, if
+	// it is known. Otherwise, the size is determined when the
+	// io.Reader is an io.Seeker.
+	TotalBytes int64
+}
+
+// DefaultDecodeOptions returns some default DecodeOptions.
+// Users can take the result and modify specific parameters.
+func DefaultDecodeOptions() DecodeOptions {
+	return DecodeOptions{
+		DissolveConvention:   DissolvePreferDissolve,
+		Mode:                 common.ScanModeStandard,
+		WarningHandler:       nil,
+		ContextCheckInterval: common.DefaultContextCheckInterval,
+		ProgressHandler:      nil,
+		ProgressInterval:     common.DefaultProgressInterval,
+		TotalBytes:           0,
 	}
 }
 
@@ -36,35 +145,104 @@ type Decoder interface {
 	// through the io.Reader, into a Library model.
 	//
 	// If decoding fails for some reason, an error is returned.
+	// Invalid constructs are reported through a *common.ParseError
+	// that points to the offending element.
 	Decode(io.Reader) (*Library, error)
+
+	// DecodeContext is like Decode but stops decoding once the
+	// specified context.Context is done, in which case the error
+	// of the context is returned.
+	DecodeContext(context.Context, io.Reader) (*Library, error)
 }
 
 // NewDecoder creates a new Decoder instance with the
 // specified DecodeLimits.
+//
+// The decoder uses the options returned by DefaultDecodeOptions.
 func NewDecoder(limits DecodeLimits) Decoder {
+	return NewDecoderWithOptions(limits, DefaultDecodeOptions())
+}
+
+// NewDecoderWithOptions creates a new Decoder instance with the
+// specified DecodeLimits and DecodeOptions.
+func NewDecoderWithOptions(limits DecodeLimits, options DecodeOptions) Decoder {
+	limits = limits.withDefaults()
 	return &decoder{
 		limits:  &limits,
-		scanner: mtlscan.NewScanner(),
+		options: &options,
 	}
 }
 
 type decoder struct {
 	limits  *DecodeLimits
-	scanner common.Scanner
+	options *DecodeOptions
 }
 
 func (d *decoder) Decode(reader io.Reader) (*Library, error) {
-	context := newDecodeContext(d.limits)
-	err := d.scanner.Scan(reader, context.HandleEvent)
+	return d.DecodeContext(context.Background(), reader)
+}
+
+func (d *decoder) DecodeContext(ctx context.Context, reader io.Reader) (*Library, error) {
+	scanner := mtlscan.NewScannerWithOptions(common.ScanOptions{
+		MaxLineLength:        d.limits.MaxLineLength,
+		Mode:                 d.options.Mode,
+		WarningHandler:       d.options.WarningHandler,
+		ContextCheckInterval: d.options.ContextCheckInterval,
+		ProgressHandler:      d.options.ProgressHandler,
+		ProgressInterval:     d.options.ProgressInterval,
+		TotalBytes:           d.options.TotalBytes,
+	}).(common.PositionedScanner)
+	decodeCtx := newDecodeContext(d.limits, d.options)
+	err := scanner.ScanPositioned(ctx, reader, func(event common.Event, source common.EventSource) error {
+		err := decodeCtx.HandleEvent(event)
+		if err == nil || d.skipInvalidConstruct(source, err) {
+			return nil
+		}
+		return d.locateInvalidConstruct(source, err)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return context.Library(), nil
+	return decodeCtx.Library(), nil
+}
+
+// skipInvalidConstruct reports the specified error as a warning and
+// returns true, if the decoder is lenient and the error was caused
+// by an invalid construct.
+func (d *decoder) skipInvalidConstruct(source common.EventSource, err error) bool {
+	if d.options.Mode != common.ScanModeLenient || !errors.Is(err, common.ErrInvalid) {
+		return false
+	}
+	if d.options.WarningHandler != nil {
+		d.options.WarningHandler(common.Warning{
+			Line:    source.Line,
+			Column:  source.Column,
+			Command: source.Command,
+			Err:     err,
+		})
+	}
+	return true
 }
 
-func newDecodeContext(limits *DecodeLimits) *decodeContext {
+// locateInvalidConstruct wraps the specified error in a
+// *common.ParseError that points to the specified source, if
+// the error was caused by an invalid construct.
+func (d *decoder) locateInvalidConstruct(source common.EventSource, err error) error {
+	if !errors.Is(err, common.ErrInvalid) {
+		return err
+	}
+	return &common.ParseError{
+		Line:    source.Line,
+		Column:  source.Column,
+		Command: source.Command,
+		Err:     err,
+	}
+}
+
+func newDecodeContext(limits *DecodeLimits, options *DecodeOptions) *decodeContext {
 	return &decodeContext{
 		limits:          limits,
+		options:         options,
 		library:         new(Library),
 		currentMaterial: nil,
 	}
@@ -72,8 +250,16 @@ func newDecodeContext(limits *DecodeLimits) *decodeContext {
 
 type decodeContext struct {
 	limits          *DecodeLimits
+	options         *DecodeOptions
 	library         *Library
 	currentMaterial *Material
+
+	// hasDissolve and hasTransparency track the declarations
+	// of the current material that affect its dissolve.
+	hasDissolve     bool
+	hasTransparency bool
+
+	unknownCommandCount int
 }
 
 func (c *decodeContext) Library() *Library {
@@ -94,10 +280,36 @@ func (c *decodeContext) HandleEvent(event common.Event) error {
 		return c.handleEmissiveColor(actual)
 	case mtlscan.RGBTransmissionFilterEvent:
 		return c.handleTransmissionFilter(actual)
+	case mtlscan.XYZAmbientColorEvent:
+		return c.handleXYZAmbientColor(actual)
+	case mtlscan.SpectralAmbientColorEvent:
+		return c.handleSpectralAmbientColor(actual)
+	case mtlscan.XYZDiffuseColorEvent:
+		return c.handleXYZDiffuseColor(actual)
+	case mtlscan.SpectralDiffuseColorEvent:
+		return c.handleSpectralDiffuseColor(actual)
+	case mtlscan.XYZSpecularColorEvent:
+		return c.handleXYZSpecularColor(actual)
+	case mtlscan.SpectralSpecularColorEvent:
+		return c.handleSpectralSpecularColor(actual)
+	case mtlscan.XYZEmissiveColorEvent:
+		return c.handleXYZEmissiveColor(actual)
+	case mtlscan.SpectralEmissiveColorEvent:
+		return c.handleSpectralEmissiveColor(actual)
+	case mtlscan.XYZTransmissionFilterEvent:
+		return c.handleXYZTransmissionFilter(actual)
+	case mtlscan.SpectralTransmissionFilterEvent:
+		return c.handleSpectralTransmissionFilter(actual)
 	case mtlscan.SpecularExponentEvent:
 		return c.handleSpecularExponent(actual)
 	case mtlscan.DissolveEvent:
 		return c.handleDissolve(actual)
+	case mtlscan.TransparencyEvent:
+		return c.handleTransparency(actual)
+	case mtlscan.OpticalDensityEvent:
+		return c.handleOpticalDensity(actual)
+	case mtlscan.SharpnessEvent:
+		return c.handleSharpness(actual)
 	case mtlscan.IlluminationEvent:
 		return c.handleIllumination(actual)
 	case mtlscan.AmbientTextureEvent:
@@ -114,6 +326,38 @@ func (c *decodeContext) HandleEvent(event common.Event) error {
 		return c.handleDissolveTexture(actual)
 	case mtlscan.BumpTextureEvent:
 		return c.handleBumpTexture(actual)
+	case mtlscan.DisplacementTextureEvent:
+		return c.handleDisplacementTexture(actual)
+	case mtlscan.DecalTextureEvent:
+		return c.handleDecalTexture(actual)
+	case mtlscan.ReflectionTextureEvent:
+		return c.handleReflectionTexture(actual)
+	case mtlscan.RoughnessEvent:
+		return c.handleRoughness(actual)
+	case mtlscan.MetallicEvent:
+		return c.handleMetallic(actual)
+	case mtlscan.SheenEvent:
+		return c.handleSheen(actual)
+	case mtlscan.ClearcoatThicknessEvent:
+		return c.handleClearcoatThickness(actual)
+	case mtlscan.ClearcoatRoughnessEvent:
+		return c.handleClearcoatRoughness(actual)
+	case mtlscan.AnisotropyEvent:
+		return c.handleAnisotropy(actual)
+	case mtlscan.AnisotropyRotationEvent:
+		return c.handleAnisotropyRotation(actual)
+	case mtlscan.RoughnessTextureEvent:
+		return c.handleRoughnessTexture(actual)
+	case mtlscan.MetallicTextureEvent:
+		return c.handleMetallicTexture(actual)
+	case mtlscan.SheenTextureEvent:
+		return c.handleSheenTexture(actual)
+	case mtlscan.RMATextureEvent:
+		return c.handleRMATexture(actual)
+	case mtlscan.NormalTextureEvent:
+		return c.handleNormalTexture(actual)
+	case common.UnknownCommandEvent:
+		return c.handleUnknownCommand(actual)
 	}
 	return nil
 }
@@ -124,6 +368,8 @@ func (c *decodeContext) handleMaterial(event mtlscan.MaterialEvent) error {
 	}
 	c.currentMaterial = DefaultMaterial()
 	c.currentMaterial.Name = event.MaterialName
+	c.hasDissolve = false
+	c.hasTransparency = false
 	c.library.Materials = append(c.library.Materials, c.currentMaterial)
 	return nil
 }
@@ -137,6 +383,31 @@ func (c *decodeContext) handleAmbientColor(event mtlscan.RGBAmbientColorEvent) e
 		G: event.G,
 		B: event.B,
 	}
+	c.currentMaterial.AmbientColorXYZ = nil
+	c.currentMaterial.AmbientColorSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleXYZAmbientColor(event mtlscan.XYZAmbientColorEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	color := XYZColor(event)
+	c.currentMaterial.AmbientColor = color.LinearRGB()
+	c.currentMaterial.AmbientColorXYZ = &color
+	c.currentMaterial.AmbientColorSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleSpectralAmbientColor(event mtlscan.SpectralAmbientColorEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.AmbientColorXYZ = nil
+	c.currentMaterial.AmbientColorSpectral = &SpectralColor{
+		FilePath: event.FilePath,
+		Factor:   event.Factor,
+	}
 	return nil
 }
 
@@ -149,6 +420,31 @@ func (c *decodeContext) handleDiffuseColor(event mtlscan.RGBDiffuseColorEvent) e
 		G: event.G,
 		B: event.B,
 	}
+	c.currentMaterial.DiffuseColorXYZ = nil
+	c.currentMaterial.DiffuseColorSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleXYZDiffuseColor(event mtlscan.XYZDiffuseColorEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	color := XYZColor(event)
+	c.currentMaterial.DiffuseColor = color.LinearRGB()
+	c.currentMaterial.DiffuseColorXYZ = &color
+	c.currentMaterial.DiffuseColorSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleSpectralDiffuseColor(event mtlscan.SpectralDiffuseColorEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.DiffuseColorXYZ = nil
+	c.currentMaterial.DiffuseColorSpectral = &SpectralColor{
+		FilePath: event.FilePath,
+		Factor:   event.Factor,
+	}
 	return nil
 }
 
@@ -161,6 +457,31 @@ func (c *decodeContext) handleSpecularColor(event mtlscan.RGBSpecularColorEvent)
 		G: event.G,
 		B: event.B,
 	}
+	c.currentMaterial.SpecularColorXYZ = nil
+	c.currentMaterial.SpecularColorSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleXYZSpecularColor(event mtlscan.XYZSpecularColorEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	color := XYZColor(event)
+	c.currentMaterial.SpecularColor = color.LinearRGB()
+	c.currentMaterial.SpecularColorXYZ = &color
+	c.currentMaterial.SpecularColorSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleSpectralSpecularColor(event mtlscan.SpectralSpecularColorEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.SpecularColorXYZ = nil
+	c.currentMaterial.SpecularColorSpectral = &SpectralColor{
+		FilePath: event.FilePath,
+		Factor:   event.Factor,
+	}
 	return nil
 }
 
@@ -173,6 +494,31 @@ func (c *decodeContext) handleEmissiveColor(event mtlscan.RGBEmissiveColorEvent)
 		G: event.G,
 		B: event.B,
 	}
+	c.currentMaterial.EmissiveColorXYZ = nil
+	c.currentMaterial.EmissiveColorSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleXYZEmissiveColor(event mtlscan.XYZEmissiveColorEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	color := XYZColor(event)
+	c.currentMaterial.EmissiveColor = color.LinearRGB()
+	c.currentMaterial.EmissiveColorXYZ = &color
+	c.currentMaterial.EmissiveColorSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleSpectralEmissiveColor(event mtlscan.SpectralEmissiveColorEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.EmissiveColorXYZ = nil
+	c.currentMaterial.EmissiveColorSpectral = &SpectralColor{
+		FilePath: event.FilePath,
+		Factor:   event.Factor,
+	}
 	return nil
 }
 
@@ -185,6 +531,31 @@ func (c *decodeContext) handleTransmissionFilter(event mtlscan.RGBTransmissionFi
 		G: event.G,
 		B: event.B,
 	}
+	c.currentMaterial.TransmissionFilterXYZ = nil
+	c.currentMaterial.TransmissionFilterSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleXYZTransmissionFilter(event mtlscan.XYZTransmissionFilterEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	color := XYZColor(event)
+	c.currentMaterial.TransmissionFilter = color.LinearRGB()
+	c.currentMaterial.TransmissionFilterXYZ = &color
+	c.currentMaterial.TransmissionFilterSpectral = nil
+	return nil
+}
+
+func (c *decodeContext) handleSpectralTransmissionFilter(event mtlscan.SpectralTransmissionFilterEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.TransmissionFilterXYZ = nil
+	c.currentMaterial.TransmissionFilterSpectral = &SpectralColor{
+		FilePath: event.FilePath,
+		Factor:   event.Factor,
+	}
 	return nil
 }
 
@@ -200,7 +571,41 @@ func (c *decodeContext) handleDissolve(event mtlscan.DissolveEvent) error {
 	if c.currentMaterial == nil {
 		return c.newMissingMaterialError()
 	}
+	c.hasDissolve = true
+	if c.hasTransparency && c.options.DissolveConvention == DissolvePreferTransparency {
+		return nil
+	}
 	c.currentMaterial.Dissolve = event.Amount
+	c.currentMaterial.DissolveHalo = event.Halo
+	return nil
+}
+
+func (c *decodeContext) handleTransparency(event mtlscan.TransparencyEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.hasTransparency = true
+	if c.hasDissolve && c.options.DissolveConvention == DissolvePreferDissolve {
+		return nil
+	}
+	c.currentMaterial.Dissolve = 1.0 - event.Amount
+	c.currentMaterial.DissolveHalo = false
+	return nil
+}
+
+func (c *decodeContext) handleOpticalDensity(event mtlscan.OpticalDensityEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.OpticalDensity = event.Amount
+	return nil
+}
+
+func (c *decodeContext) handleSharpness(event mtlscan.SharpnessEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.Sharpness = event.Amount
 	return nil
 }
 
@@ -217,6 +622,7 @@ func (c *decodeContext) handleAmbientTexture(event mtlscan.AmbientTextureEvent)
 		return c.newMissingMaterialError()
 	}
 	c.currentMaterial.AmbientTexture = event.TexturePath
+	c.currentMaterial.AmbientTextureOptions = convertTextureOptions(event.Options)
 	return nil
 }
 
@@ -225,6 +631,7 @@ func (c *decodeContext) handleDiffuseTexture(event mtlscan.DiffuseTextureEvent)
 		return c.newMissingMaterialError()
 	}
 	c.currentMaterial.DiffuseTexture = event.TexturePath
+	c.currentMaterial.DiffuseTextureOptions = convertTextureOptions(event.Options)
 	return nil
 }
 
@@ -233,6 +640,7 @@ func (c *decodeContext) handleSpecularTexture(event mtlscan.SpecularTextureEvent
 		return c.newMissingMaterialError()
 	}
 	c.currentMaterial.SpecularTexture = event.TexturePath
+	c.currentMaterial.SpecularTextureOptions = convertTextureOptions(event.Options)
 	return nil
 }
 
@@ -241,6 +649,7 @@ func (c *decodeContext) handleEmissiveTexture(event mtlscan.EmissiveTextureEvent
 		return c.newMissingMaterialError()
 	}
 	c.currentMaterial.EmissiveTexture = event.TexturePath
+	c.currentMaterial.EmissiveTextureOptions = convertTextureOptions(event.Options)
 	return nil
 }
 
@@ -249,6 +658,7 @@ func (c *decodeContext) handleSpecularExponentTexture(event mtlscan.SpecularExpo
 		return c.newMissingMaterialError()
 	}
 	c.currentMaterial.SpecularExponentTexture = event.TexturePath
+	c.currentMaterial.SpecularExponentTextureOptions = convertTextureOptions(event.Options)
 	return nil
 }
 
@@ -257,6 +667,7 @@ func (c *decodeContext) handleDissolveTexture(event mtlscan.DissolveTextureEvent
 		return c.newMissingMaterialError()
 	}
 	c.currentMaterial.DissolveTexture = event.TexturePath
+	c.currentMaterial.DissolveTextureOptions = convertTextureOptions(event.Options)
 	return nil
 }
 
@@ -265,6 +676,196 @@ func (c *decodeContext) handleBumpTexture(event mtlscan.BumpTextureEvent) error
 		return c.newMissingMaterialError()
 	}
 	c.currentMaterial.BumpTexture = event.TexturePath
+	c.currentMaterial.BumpTextureOptions = convertTextureOptions(event.Options)
+	return nil
+}
+
+func (c *decodeContext) handleRoughness(event mtlscan.RoughnessEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.Roughness = event.Amount
+	return nil
+}
+
+func (c *decodeContext) handleMetallic(event mtlscan.MetallicEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.Metallic = event.Amount
+	return nil
+}
+
+func (c *decodeContext) handleSheen(event mtlscan.SheenEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.Sheen = event.Amount
+	return nil
+}
+
+func (c *decodeContext) handleClearcoatThickness(event mtlscan.ClearcoatThicknessEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.ClearcoatThickness = event.Amount
+	return nil
+}
+
+func (c *decodeContext) handleClearcoatRoughness(event mtlscan.ClearcoatRoughnessEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.ClearcoatRoughness = event.Amount
+	return nil
+}
+
+func (c *decodeContext) handleAnisotropy(event mtlscan.AnisotropyEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.Anisotropy = event.Amount
+	return nil
+}
+
+func (c *decodeContext) handleAnisotropyRotation(event mtlscan.AnisotropyRotationEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.AnisotropyRotation = event.Amount
+	return nil
+}
+
+func (c *decodeContext) handleRoughnessTexture(event mtlscan.RoughnessTextureEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.RoughnessTexture = event.TexturePath
+	c.currentMaterial.RoughnessTextureOptions = convertTextureOptions(event.Options)
+	return nil
+}
+
+func (c *decodeContext) handleMetallicTexture(event mtlscan.MetallicTextureEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.MetallicTexture = event.TexturePath
+	c.currentMaterial.MetallicTextureOptions = convertTextureOptions(event.Options)
+	return nil
+}
+
+func (c *decodeContext) handleSheenTexture(event mtlscan.SheenTextureEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.SheenTexture = event.TexturePath
+	c.currentMaterial.SheenTextureOptions = convertTextureOptions(event.Options)
+	return nil
+}
+
+func (c *decodeContext) handleRMATexture(event mtlscan.RMATextureEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.RMATexture = event.TexturePath
+	c.currentMaterial.RMATextureOptions = convertTextureOptions(event.Options)
+	return nil
+}
+
+func (c *decodeContext) handleNormalTexture(event mtlscan.NormalTextureEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.NormalTexture = event.TexturePath
+	c.currentMaterial.NormalTextureOptions = convertTextureOptions(event.Options)
+	return nil
+}
+
+func (c *decodeContext) handleDisplacementTexture(event mtlscan.DisplacementTextureEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.DisplacementTexture = event.TexturePath
+	c.currentMaterial.DisplacementTextureOptions = convertTextureOptions(event.Options)
+	return nil
+}
+
+func (c *decodeContext) handleDecalTexture(event mtlscan.DecalTextureEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	c.currentMaterial.DecalTexture = event.TexturePath
+	c.currentMaterial.DecalTextureOptions = convertTextureOptions(event.Options)
+	return nil
+}
+
+func (c *decodeContext) handleReflectionTexture(event mtlscan.ReflectionTextureEvent) error {
+	if c.currentMaterial == nil {
+		return c.newMissingMaterialError()
+	}
+	material := c.currentMaterial
+	var (
+		path    *string
+		options *TextureOptions
+	)
+	switch event.Options.Type {
+	case "cube_top":
+		path, options = &material.ReflectionCubeTopTexture, &material.ReflectionCubeTopTextureOptions
+	case "cube_bottom":
+		path, options = &material.ReflectionCubeBottomTexture, &material.ReflectionCubeBottomTextureOptions
+	case "cube_front":
+		path, options = &material.ReflectionCubeFrontTexture, &material.ReflectionCubeFrontTextureOptions
+	case "cube_back":
+		path, options = &material.ReflectionCubeBackTexture, &material.ReflectionCubeBackTextureOptions
+	case "cube_left":
+		path, options = &material.ReflectionCubeLeftTexture, &material.ReflectionCubeLeftTextureOptions
+	case "cube_right":
+		path, options = &material.ReflectionCubeRightTexture, &material.ReflectionCubeRightTextureOptions
+	default:
+		path, options = &material.ReflectionTexture, &material.ReflectionTextureOptions
+	}
+	*path = event.TexturePath
+	*options = convertTextureOptions(event.Options)
+	return nil
+}
+
+func convertTextureOptions(options mtlscan.TextureOptions) TextureOptions {
+	return TextureOptions{
+		BlendU:          options.BlendU,
+		BlendV:          options.BlendV,
+		BumpMultiplier:  options.BumpMultiplier,
+		Boost:           options.Boost,
+		ColorCorrection: options.ColorCorrection,
+		Clamp:           options.Clamp,
+		Channel:         options.Channel,
+		RangeBase:       options.RangeBase,
+		RangeGain:       options.RangeGain,
+		Offset:          TextureVector(options.Offset),
+		Scale:           TextureVector(options.Scale),
+		Turbulence:      TextureVector(options.Turbulence),
+		Resolution:      options.Resolution,
+		Type:            options.Type,
+	}
+}
+
+func (c *decodeContext) handleUnknownCommand(event common.UnknownCommandEvent) error {
+	// Unknown commands do not affect the library, so there is
+	// no need to fail the decoding once the limit is reached.
+	if c.unknownCommandCount >= c.limits.MaxUnknownCommandCount {
+		return nil
+	}
+	c.unknownCommandCount++
+	command := UnknownCommand{
+		Name:   event.CommandName,
+		Params: event.Params,
+	}
+	// Commands that appear before the first material
+	// declaration apply to the library as a whole.
+	if c.currentMaterial == nil {
+		c.library.UnknownCommands = append(c.library.UnknownCommands, command)
+	} else {
+		c.currentMaterial.UnknownCommands = append(c.currentMaterial.UnknownCommands, command)
+	}
 	return nil
 }
 
@@ -1,12 +1,16 @@
 package mtl_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/mokiat/go-data-front/common"
 	"github.com/mokiat/go-data-front/decoder/mtl"
 )
 
@@ -14,6 +18,7 @@ var _ = Describe("Decoder", func() {
 	var (
 		testFile string
 		limits   mtl.DecodeLimits
+		options  mtl.DecodeOptions
 
 		library   *mtl.Library
 		decodeErr error
@@ -38,12 +43,13 @@ var _ = Describe("Decoder", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer file.Close()
 
-		decoder := mtl.NewDecoder(limits)
+		decoder := mtl.NewDecoderWithOptions(limits, options)
 		library, decodeErr = decoder.Decode(file)
 	})
 
 	BeforeEach(func() {
 		limits = mtl.DefaultLimits()
+		options = mtl.DefaultDecodeOptions()
 	})
 
 	When("a basic file is decoded", func() {
@@ -150,6 +156,186 @@ var _ = Describe("Decoder", func() {
 		})
 	})
 
+	When("a file with spectral and xyz colors is decoded", func() {
+		var material *mtl.Material
+
+		BeforeEach(func() {
+			testFile = "valid_alternative_colors.mtl"
+		})
+
+		JustBeforeEach(func() {
+			material = library.Materials[0]
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have converted xyz colors to linear sRGB", func() {
+			Expect(material.AmbientColorXYZ).To(Equal(&mtl.XYZColor{
+				X: 0.95047, Y: 1.0, Z: 1.08883,
+			}))
+			Expect(material.AmbientColor.R).To(BeNumerically("~", 1.0, 0.001))
+			Expect(material.AmbientColor.G).To(BeNumerically("~", 1.0, 0.001))
+			Expect(material.AmbientColor.B).To(BeNumerically("~", 1.0, 0.001))
+		})
+
+		It("should have recorded spectral colors", func() {
+			Expect(material.DiffuseColorSpectral).To(Equal(&mtl.SpectralColor{
+				FilePath: "diffuse.rfl",
+				Factor:   0.5,
+			}))
+			Expect(material.DiffuseColor).To(Equal(mtl.RGBColor{R: 1.0, G: 1.0, B: 1.0}))
+		})
+
+		It("should have used the last declaration of each color", func() {
+			Expect(material.SpecularColorXYZ).To(Equal(&mtl.XYZColor{X: 0.3, Y: 0.4, Z: 0.5}))
+			Expect(material.EmissiveColorXYZ).To(BeNil())
+			Expect(material.EmissiveColor).To(Equal(mtl.RGBColor{R: 0.1, G: 0.2, B: 0.3}))
+		})
+
+		It("should have preserved the RGB value of spectral colors", func() {
+			Expect(material.TransmissionFilter).To(Equal(mtl.RGBColor{R: 0.5, G: 0.5, B: 0.5}))
+			Expect(material.TransmissionFilterSpectral).To(Equal(&mtl.SpectralColor{
+				FilePath: "transmission.rfl",
+				Factor:   1.0,
+			}))
+		})
+	})
+
+	When("a file with texture options is decoded", func() {
+		BeforeEach(func() {
+			testFile = "valid_texture_options.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have decoded the texture options", func() {
+			material := library.Materials[0]
+
+			diffuseOptions := mtl.DefaultTextureOptions()
+			diffuseOptions.Scale = mtl.TextureVector{U: 2.0, V: 2.0, W: 1.0}
+			diffuseOptions.Clamp = true
+			Expect(material.DiffuseTexture).To(Equal("diffuse.png"))
+			Expect(material.DiffuseTextureOptions).To(Equal(diffuseOptions))
+
+			bumpOptions := mtl.DefaultTextureOptions()
+			bumpOptions.BumpMultiplier = 0.5
+			bumpOptions.Channel = "l"
+			Expect(material.BumpTexture).To(Equal("bump.png"))
+			Expect(material.BumpTextureOptions).To(Equal(bumpOptions))
+
+			Expect(material.AmbientTexture).To(Equal("ambient.png"))
+			Expect(material.AmbientTextureOptions).To(Equal(mtl.DefaultTextureOptions()))
+		})
+	})
+
+	When("a file with dissolve and transparency declarations is decoded", func() {
+		BeforeEach(func() {
+			testFile = "valid_dissolve_conventions.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have preferred dissolve declarations", func() {
+			Expect(library.Materials[0].Dissolve).To(BeNumerically("~", 0.4))
+			Expect(library.Materials[1].Dissolve).To(BeNumerically("~", 0.4))
+		})
+
+		It("should have inverted transparency declarations", func() {
+			Expect(library.Materials[2].Dissolve).To(BeNumerically("~", 0.9))
+		})
+
+		It("should have decoded the optical values", func() {
+			material := library.Materials[3]
+			Expect(material.Dissolve).To(BeNumerically("~", 0.6))
+			Expect(material.DissolveHalo).To(BeTrue())
+			Expect(material.OpticalDensity).To(Equal(1.5))
+			Expect(material.Sharpness).To(Equal(200.0))
+		})
+
+		When("transparency declarations are preferred", func() {
+			BeforeEach(func() {
+				options.DissolveConvention = mtl.DissolvePreferTransparency
+			})
+
+			It("should have preferred transparency declarations", func() {
+				Expect(library.Materials[0].Dissolve).To(BeNumerically("~", 0.75))
+				Expect(library.Materials[1].Dissolve).To(BeNumerically("~", 0.75))
+				Expect(library.Materials[2].Dissolve).To(BeNumerically("~", 0.9))
+			})
+		})
+
+		When("the last declaration is preferred", func() {
+			BeforeEach(func() {
+				options.DissolveConvention = mtl.DissolveLastDeclared
+			})
+
+			It("should have used the last declarations", func() {
+				Expect(library.Materials[0].Dissolve).To(BeNumerically("~", 0.75))
+				Expect(library.Materials[1].Dissolve).To(BeNumerically("~", 0.4))
+				Expect(library.Materials[2].Dissolve).To(BeNumerically("~", 0.9))
+			})
+		})
+	})
+
+	When("a file with displacement, decal, and reflection textures is decoded", func() {
+		BeforeEach(func() {
+			testFile = "valid_extra_textures.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have decoded the last bump texture", func() {
+			Expect(library.Materials[0].BumpTexture).To(Equal("bump_upper.png"))
+		})
+
+		It("should have decoded the displacement and decal textures", func() {
+			material := library.Materials[0]
+			Expect(material.DisplacementTexture).To(Equal("displacement.png"))
+			Expect(material.DecalTexture).To(Equal("decal.png"))
+		})
+
+		It("should have decoded the reflection textures", func() {
+			material := library.Materials[0]
+			Expect(material.ReflectionTexture).To(Equal("sphere.png"))
+			Expect(material.ReflectionTextureOptions.Type).To(Equal("sphere"))
+			Expect(material.ReflectionCubeTopTexture).To(Equal("top.png"))
+			Expect(material.ReflectionCubeBottomTexture).To(Equal("bottom.png"))
+			Expect(material.ReflectionCubeFrontTexture).To(Equal("front.png"))
+			Expect(material.ReflectionCubeBackTexture).To(Equal("back.png"))
+			Expect(material.ReflectionCubeLeftTexture).To(Equal("left.png"))
+			Expect(material.ReflectionCubeRightTexture).To(Equal("right.png"))
+		})
+	})
+
+	When("a file with PBR declarations is decoded", func() {
+		BeforeEach(func() {
+			testFile = "valid_pbr.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have decoded the PBR factors", func() {
+			material := library.Materials[0]
+			Expect(material.Roughness).To(Equal(0.25))
+			Expect(material.Metallic).To(Equal(1.0))
+			Expect(material.Sheen).To(Equal(0.1))
+			Expect(material.ClearcoatThickness).To(Equal(0.2))
+			Expect(material.ClearcoatRoughness).To(Equal(0.3))
+			Expect(material.Anisotropy).To(Equal(0.4))
+			Expect(material.AnisotropyRotation).To(Equal(0.5))
+		})
+
+		It("should have decoded the PBR textures", func() {
+			material := library.Materials[0]
+			Expect(material.RoughnessTexture).To(Equal("roughness.png"))
+			Expect(material.MetallicTexture).To(Equal("metallic.png"))
+			Expect(material.SheenTexture).To(Equal("sheen.png"))
+			Expect(material.RMATexture).To(Equal("rma.png"))
+			Expect(material.NormalTexture).To(Equal("normal.png"))
+			Expect(material.NormalTextureOptions.BumpMultiplier).To(Equal(0.5))
+		})
+	})
+
 	When("a file with multiple materials is decoded", func() {
 		BeforeEach(func() {
 			testFile = "valid_multiple_materials.mtl"
@@ -158,9 +344,10 @@ var _ = Describe("Decoder", func() {
 		itShouldNotHaveReturnedAnError()
 
 		It("should have decoded all materials", func() {
-			Expect(library.Materials).To(HaveLen(2))
+			Expect(library.Materials).To(HaveLen(3))
 			Expect(library.Materials[0].Name).To(Equal("FirstMaterial"))
 			Expect(library.Materials[1].Name).To(Equal("SecondMaterial"))
+			Expect(library.Materials[2].Name).To(Equal("Third Material"))
 		})
 
 		When("the number of materials is larger than the limit", func() {
@@ -172,6 +359,172 @@ var _ = Describe("Decoder", func() {
 		})
 	})
 
+	When("a file with unknown commands is decoded", func() {
+		BeforeEach(func() {
+			testFile = "valid_unknown_commands.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have assigned commands before any material to the library", func() {
+			Expect(library.UnknownCommands).To(Equal([]mtl.UnknownCommand{
+				{Name: "vendor_version", Params: []string{"2"}},
+			}))
+		})
+
+		It("should have assigned the remaining commands to the material", func() {
+			Expect(library.Materials).To(HaveLen(1))
+			Expect(library.Materials[0].UnknownCommands).To(Equal([]mtl.UnknownCommand{
+				{Name: "map_custom", Params: []string{"texture.png", "-o", "1"}},
+				{Name: "double_sided", Params: []string{}},
+			}))
+		})
+
+		When("the number of unknown commands is larger than the limit", func() {
+			BeforeEach(func() {
+				limits.MaxUnknownCommandCount = 2
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have collected unknown commands up to the limit", func() {
+				Expect(library.UnknownCommands).To(HaveLen(1))
+				Expect(library.Materials[0].UnknownCommands).To(Equal([]mtl.UnknownCommand{
+					{Name: "map_custom", Params: []string{"texture.png", "-o", "1"}},
+				}))
+			})
+		})
+
+		When("the unknown command limit is not specified", func() {
+			BeforeEach(func() {
+				limits = mtl.DecodeLimits{
+					MaxMaterialCount: 5,
+				}
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have collected all unknown commands", func() {
+				Expect(library.UnknownCommands).To(HaveLen(1))
+				Expect(library.Materials[0].UnknownCommands).To(HaveLen(2))
+			})
+		})
+	})
+
+	When("a file with invalid constructs is decoded", func() {
+		BeforeEach(func() {
+			testFile = "error_invalid_constructs.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		It("should have pointed to the invalid construct", func() {
+			var parseErr *common.ParseError
+			Expect(errors.As(decodeErr, &parseErr)).To(BeTrue())
+			Expect(parseErr.Line).To(Equal(1))
+			Expect(parseErr.Column).To(Equal(1))
+			Expect(parseErr.Command).To(Equal("Kd"))
+			Expect(parseErr).To(MatchError(common.ErrInvalid))
+		})
+
+		When("decoding in lenient mode", func() {
+			var warnings []common.Warning
+
+			BeforeEach(func() {
+				warnings = nil
+				options.Mode = common.ScanModeLenient
+				options.WarningHandler = func(warning common.Warning) {
+					warnings = append(warnings, warning)
+				}
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have skipped the invalid constructs", func() {
+				Expect(library.Materials).To(HaveLen(1))
+				Expect(library.Materials[0].DiffuseColor).To(Equal(mtl.DefaultMaterial().DiffuseColor))
+				Expect(library.Materials[0].SpecularExponent).To(Equal(20.0))
+			})
+
+			It("should have reported the invalid constructs", func() {
+				Expect(warnings).To(HaveLen(2))
+				Expect(warnings[0].Line).To(Equal(1))
+				Expect(warnings[0].Column).To(Equal(1))
+				Expect(warnings[0].Command).To(Equal("Kd"))
+				Expect(warnings[0].Err).To(MatchError(common.ErrInvalid))
+				Expect(warnings[1].Line).To(Equal(3))
+				Expect(warnings[1].Column).To(Equal(4))
+				Expect(warnings[1].Command).To(Equal("Ns"))
+			})
+		})
+	})
+
+	When("a file with unknown commands is decoded in strict mode", func() {
+		BeforeEach(func() {
+			testFile = "valid_unknown_commands.mtl"
+			options.Mode = common.ScanModeStrict
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("decoding with a progress handler", func() {
+		var reports []common.Progress
+
+		BeforeEach(func() {
+			reports = nil
+			testFile = "valid_basic.mtl"
+			options.ProgressHandler = func(progress common.Progress) {
+				reports = append(reports, progress)
+			}
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have reported the final progress", func() {
+			Expect(reports).ToNot(BeEmpty())
+			final := reports[len(reports)-1]
+			Expect(final.BytesRead).To(Equal(int64(305)))
+			Expect(final.TotalBytes).To(Equal(int64(305)))
+			Expect(final.Elements).To(Equal(16))
+		})
+	})
+
+	When("the line length limit is not specified", func() {
+		BeforeEach(func() {
+			testFile = "valid_basic.mtl"
+			limits.MaxLineLength = 0
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have applied the default line length limit", func() {
+			content := "#" + strings.Repeat(" ", common.DefaultMaxLineLength) + "\n"
+			decoder := mtl.NewDecoderWithOptions(limits, options)
+			_, err := decoder.Decode(strings.NewReader(content))
+			Expect(err).To(MatchError(common.ErrLimitsExceeded))
+		})
+	})
+
+	When("decoding with a cancelled context", func() {
+		BeforeEach(func() {
+			testFile = "valid_basic.mtl"
+		})
+
+		It("should have returned the context error", func() {
+			file, err := os.Open(filepath.Join("testdata", testFile))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			decoder := mtl.NewDecoderWithOptions(limits, options)
+			_, err = decoder.DecodeContext(ctx, file)
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+
 	When("decoding ambient color without material", func() {
 		BeforeEach(func() {
 			testFile = "error_ambient_color_no_material.mtl"
@@ -284,6 +637,38 @@ var _ = Describe("Decoder", func() {
 		itShouldHaveReturnedAnError()
 	})
 
+	When("decoding xyz color without material", func() {
+		BeforeEach(func() {
+			testFile = "error_xyz_color_no_material.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("decoding transparency without material", func() {
+		BeforeEach(func() {
+			testFile = "error_transparency_no_material.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("decoding reflection texture without material", func() {
+		BeforeEach(func() {
+			testFile = "error_reflection_texture_no_material.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("decoding roughness without material", func() {
+		BeforeEach(func() {
+			testFile = "error_roughness_no_material.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
 	When("decoding bump texture without material", func() {
 		BeforeEach(func() {
 			testFile = "error_bump_texture_no_material.mtl"
@@ -304,5 +689,51 @@ var _ = Describe("DecodeLimits", func() {
 		Specify("default material limit should be 512", func() {
 			Expect(limits.MaxMaterialCount).To(Equal(512))
 		})
+
+		Specify("default unknown command limit should be 1024", func() {
+			Expect(limits.MaxUnknownCommandCount).To(Equal(1024))
+		})
+
+		It("line length limit should be the default one", func() {
+			Expect(limits.MaxLineLength).To(Equal(common.DefaultMaxLineLength))
+		})
+	})
+})
+
+var _ = Describe("DecodeOptions", func() {
+	var options mtl.DecodeOptions
+
+	Describe("DefaultDecodeOptions", func() {
+		BeforeEach(func() {
+			options = mtl.DefaultDecodeOptions()
+		})
+
+		It("should prefer dissolve declarations", func() {
+			Expect(options.DissolveConvention).To(Equal(mtl.DissolvePreferDissolve))
+		})
+
+		It("should use the standard mode", func() {
+			Expect(options.Mode).To(Equal(common.ScanModeStandard))
+		})
+
+		It("should have no warning handler", func() {
+			Expect(options.WarningHandler).To(BeNil())
+		})
+
+		It("should use the default context check interval", func() {
+			Expect(options.ContextCheckInterval).To(Equal(common.DefaultContextCheckInterval))
+		})
+
+		It("should have no progress handler", func() {
+			Expect(options.ProgressHandler).To(BeNil())
+		})
+
+		It("should use the default progress interval", func() {
+			Expect(options.ProgressInterval).To(Equal(common.DefaultProgressInterval))
+		})
+
+		It("should not have a known total size", func() {
+			Expect(options.TotalBytes).To(BeZero())
+		})
 	})
 })
@@ -0,0 +1,304 @@
+package mtl
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// EncodeOptions specifies how a Library should be encoded.
+type EncodeOptions struct {
+
+	// OmitDefaults specifies whether material values that are
+	// equal to the ones of DefaultMaterial should be skipped.
+	//
+	// This produces smaller resources, which decode into the
+	// same Library.
+	OmitDefaults bool
+}
+
+// DefaultEncodeOptions returns some default EncodeOptions.
+// Users can take the result and modify specific parameters.
+func DefaultEncodeOptions() EncodeOptions {
+	return EncodeOptions{
+		OmitDefaults: false,
+	}
+}
+
+// Encoder is an API that allows one to encode a Library
+// model into an MTL Wavefront resource.
+type Encoder interface {
+
+	// Encode encodes the specified Library into an MTL Wavefront
+	// resource, which is written to the io.Writer.
+	//
+	// If encoding fails for some reason, an error is returned.
+	Encode(io.Writer, *Library) error
+}
+
+// NewEncoder creates a new Encoder instance with the
+// specified EncodeOptions.
+func NewEncoder(options EncodeOptions) Encoder {
+	return &encoder{
+		options: &options,
+	}
+}
+
+type encoder struct {
+	options *EncodeOptions
+}
+
+func (e *encoder) Encode(writer io.Writer, library *Library) error {
+	encodeCtx := newEncodeContext(e.options, writer)
+	encodeCtx.writeLibrary(library)
+	return encodeCtx.Flush()
+}
+
+func newEncodeContext(options *EncodeOptions, writer io.Writer) *encodeContext {
+	return &encodeContext{
+		options:  options,
+		writer:   bufio.NewWriter(writer),
+		defaults: DefaultMaterial(),
+	}
+}
+
+type encodeContext struct {
+	options  *EncodeOptions
+	writer   *bufio.Writer
+	defaults *Material
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+// and returns the first error that occurred during writing.
+func (c *encodeContext) Flush() error {
+	return c.writer.Flush()
+}
+
+func (c *encodeContext) writeLibrary(library *Library) {
+	c.writeUnknownCommands(library.UnknownCommands)
+	for i, material := range library.Materials {
+		if i > 0 || len(library.UnknownCommands) > 0 {
+			c.writer.WriteByte('\n')
+		}
+		c.writeMaterial(material)
+	}
+}
+
+func (c *encodeContext) writeMaterial(material *Material) {
+	c.writeString("newmtl", material.Name)
+	c.writeColor("Ka", material.AmbientColor, material.AmbientColorXYZ, material.AmbientColorSpectral, c.defaults.AmbientColor)
+	c.writeColor("Kd", material.DiffuseColor, material.DiffuseColorXYZ, material.DiffuseColorSpectral, c.defaults.DiffuseColor)
+	c.writeColor("Ks", material.SpecularColor, material.SpecularColorXYZ, material.SpecularColorSpectral, c.defaults.SpecularColor)
+	c.writeColor("Ke", material.EmissiveColor, material.EmissiveColorXYZ, material.EmissiveColorSpectral, c.defaults.EmissiveColor)
+	c.writeColor("Tf", material.TransmissionFilter, material.TransmissionFilterXYZ, material.TransmissionFilterSpectral, c.defaults.TransmissionFilter)
+	c.writeDissolve(material.Dissolve, material.DissolveHalo)
+	c.writeFloat("Ns", material.SpecularExponent, c.defaults.SpecularExponent)
+	c.writeFloat("Ni", material.OpticalDensity, c.defaults.OpticalDensity)
+	c.writeFloat("sharpness", material.Sharpness, c.defaults.Sharpness)
+	c.writeInt("illum", material.Illumination, c.defaults.Illumination)
+	c.writeTexture("map_Ka", material.AmbientTexture, material.AmbientTextureOptions)
+	c.writeTexture("map_Kd", material.DiffuseTexture, material.DiffuseTextureOptions)
+	c.writeTexture("map_Ks", material.SpecularTexture, material.SpecularTextureOptions)
+	c.writeTexture("map_Ke", material.EmissiveTexture, material.EmissiveTextureOptions)
+	c.writeTexture("map_Ns", material.SpecularExponentTexture, material.SpecularExponentTextureOptions)
+	c.writeTexture("map_d", material.DissolveTexture, material.DissolveTextureOptions)
+	c.writeTexture("map_Bump", material.BumpTexture, material.BumpTextureOptions)
+	c.writeTexture("disp", material.DisplacementTexture, material.DisplacementTextureOptions)
+	c.writeTexture("decal", material.DecalTexture, material.DecalTextureOptions)
+	c.writeReflectionTexture("", material.ReflectionTexture, material.ReflectionTextureOptions)
+	c.writeReflectionTexture("cube_top", material.ReflectionCubeTopTexture, material.ReflectionCubeTopTextureOptions)
+	c.writeReflectionTexture("cube_bottom", material.ReflectionCubeBottomTexture, material.ReflectionCubeBottomTextureOptions)
+	c.writeReflectionTexture("cube_front", material.ReflectionCubeFrontTexture, material.ReflectionCubeFrontTextureOptions)
+	c.writeReflectionTexture("cube_back", material.ReflectionCubeBackTexture, material.ReflectionCubeBackTextureOptions)
+	c.writeReflectionTexture("cube_left", material.ReflectionCubeLeftTexture, material.ReflectionCubeLeftTextureOptions)
+	c.writeReflectionTexture("cube_right", material.ReflectionCubeRightTexture, material.ReflectionCubeRightTextureOptions)
+	c.writeExtensionFloat("Pr", material.Roughness, c.defaults.Roughness)
+	c.writeExtensionFloat("Pm", material.Metallic, c.defaults.Metallic)
+	c.writeExtensionFloat("Ps", material.Sheen, c.defaults.Sheen)
+	c.writeExtensionFloat("Pc", material.ClearcoatThickness, c.defaults.ClearcoatThickness)
+	c.writeExtensionFloat("Pcr", material.ClearcoatRoughness, c.defaults.ClearcoatRoughness)
+	c.writeExtensionFloat("aniso", material.Anisotropy, c.defaults.Anisotropy)
+	c.writeExtensionFloat("anisor", material.AnisotropyRotation, c.defaults.AnisotropyRotation)
+	c.writeTexture("map_Pr", material.RoughnessTexture, material.RoughnessTextureOptions)
+	c.writeTexture("map_Pm", material.MetallicTexture, material.MetallicTextureOptions)
+	c.writeTexture("map_Ps", material.SheenTexture, material.SheenTextureOptions)
+	c.writeTexture("map_RMA", material.RMATexture, material.RMATextureOptions)
+	c.writeTexture("norm", material.NormalTexture, material.NormalTextureOptions)
+	c.writeUnknownCommands(material.UnknownCommands)
+}
+
+func (c *encodeContext) writeUnknownCommands(commands []UnknownCommand) {
+	for _, command := range commands {
+		c.writer.WriteString(command.Name)
+		for _, param := range command.Params {
+			c.writer.WriteByte(' ')
+			c.writer.WriteString(param)
+		}
+		c.writer.WriteByte('\n')
+	}
+}
+
+func (c *encodeContext) writeColor(name string, value RGBColor, xyz *XYZColor, spectral *SpectralColor, defaultValue RGBColor) {
+	if xyz != nil {
+		c.writer.WriteString(name)
+		c.writer.WriteString(" xyz")
+		c.writeFloats(xyz.X, xyz.Y, xyz.Z)
+		c.writer.WriteByte('\n')
+		return
+	}
+	// A spectral declaration does not affect the RGB value, so
+	// the latter is written first in order to be preserved.
+	if !c.options.OmitDefaults || value != defaultValue {
+		c.writer.WriteString(name)
+		c.writeFloats(value.R, value.G, value.B)
+		c.writer.WriteByte('\n')
+	}
+	if spectral != nil {
+		c.writer.WriteString(name)
+		c.writer.WriteString(" spectral ")
+		c.writer.WriteString(spectral.FilePath)
+		c.writeFloats(spectral.Factor)
+		c.writer.WriteByte('\n')
+	}
+}
+
+func (c *encodeContext) writeDissolve(value float64, halo bool) {
+	if c.options.OmitDefaults && value == c.defaults.Dissolve && halo == c.defaults.DissolveHalo {
+		return
+	}
+	c.writer.WriteString("d")
+	if halo {
+		c.writer.WriteString(" -halo")
+	}
+	c.writeFloats(value)
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeFloat(name string, value, defaultValue float64) {
+	if c.options.OmitDefaults && value == defaultValue {
+		return
+	}
+	c.writer.WriteString(name)
+	c.writeFloats(value)
+	c.writer.WriteByte('\n')
+}
+
+// writeExtensionFloat writes a value that is not part of the original
+// MTL specification. Such values are omitted when equal to the default
+// regardless of the options, so that libraries that do not use the
+// extension are not polluted with them.
+func (c *encodeContext) writeExtensionFloat(name string, value, defaultValue float64) {
+	if value == defaultValue {
+		return
+	}
+	c.writer.WriteString(name)
+	c.writeFloats(value)
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeInt(name string, value, defaultValue int64) {
+	if c.options.OmitDefaults && value == defaultValue {
+		return
+	}
+	c.writeString(name, strconv.FormatInt(value, 10))
+}
+
+func (c *encodeContext) writeTexture(name, path string, options TextureOptions) {
+	// An empty path indicates that there is no texture, which
+	// cannot be expressed in an MTL resource.
+	if path == "" {
+		return
+	}
+	c.writer.WriteString(name)
+	c.writeTextureOptions(options)
+	c.writer.WriteByte(' ')
+	c.writer.WriteString(path)
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeReflectionTexture(textureType, path string, options TextureOptions) {
+	// The type of cube faces is implied by the field that holds
+	// them, so it is enforced in case it was not specified.
+	if textureType != "" {
+		options.Type = textureType
+	}
+	c.writeTexture("refl", path, options)
+}
+
+func (c *encodeContext) writeTextureOptions(options TextureOptions) {
+	// Options that match the MTL defaults are always omitted,
+	// since they have no effect on the decoded texture.
+	defaults := DefaultTextureOptions()
+	if options.BlendU != defaults.BlendU {
+		c.writeTextureSwitch("-blendu", options.BlendU)
+	}
+	if options.BlendV != defaults.BlendV {
+		c.writeTextureSwitch("-blendv", options.BlendV)
+	}
+	if options.BumpMultiplier != defaults.BumpMultiplier {
+		c.writer.WriteString(" -bm")
+		c.writeFloats(options.BumpMultiplier)
+	}
+	if options.Boost != defaults.Boost {
+		c.writer.WriteString(" -boost")
+		c.writeFloats(options.Boost)
+	}
+	if options.ColorCorrection != defaults.ColorCorrection {
+		c.writeTextureSwitch("-cc", options.ColorCorrection)
+	}
+	if options.Clamp != defaults.Clamp {
+		c.writeTextureSwitch("-clamp", options.Clamp)
+	}
+	if options.Channel != defaults.Channel {
+		c.writer.WriteString(" -imfchan ")
+		c.writer.WriteString(options.Channel)
+	}
+	if options.RangeBase != defaults.RangeBase || options.RangeGain != defaults.RangeGain {
+		c.writer.WriteString(" -mm")
+		c.writeFloats(options.RangeBase, options.RangeGain)
+	}
+	if options.Offset != defaults.Offset {
+		c.writer.WriteString(" -o")
+		c.writeFloats(options.Offset.U, options.Offset.V, options.Offset.W)
+	}
+	if options.Scale != defaults.Scale {
+		c.writer.WriteString(" -s")
+		c.writeFloats(options.Scale.U, options.Scale.V, options.Scale.W)
+	}
+	if options.Turbulence != defaults.Turbulence {
+		c.writer.WriteString(" -t")
+		c.writeFloats(options.Turbulence.U, options.Turbulence.V, options.Turbulence.W)
+	}
+	if options.Resolution != defaults.Resolution {
+		c.writer.WriteString(" -texres ")
+		c.writer.WriteString(strconv.FormatInt(options.Resolution, 10))
+	}
+	if options.Type != defaults.Type {
+		c.writer.WriteString(" -type ")
+		c.writer.WriteString(options.Type)
+	}
+}
+
+func (c *encodeContext) writeTextureSwitch(name string, value bool) {
+	c.writer.WriteByte(' ')
+	c.writer.WriteString(name)
+	if value {
+		c.writer.WriteString(" on")
+	} else {
+		c.writer.WriteString(" off")
+	}
+}
+
+func (c *encodeContext) writeString(name, value string) {
+	c.writer.WriteString(name)
+	c.writer.WriteByte(' ')
+	c.writer.WriteString(value)
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeFloats(values ...float64) {
+	for _, value := range values {
+		c.writer.WriteByte(' ')
+		c.writer.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	}
+}
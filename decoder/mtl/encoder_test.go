@@ -0,0 +1,241 @@
+package mtl_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/go-data-front/decoder/mtl"
+	"github.com/mokiat/go-data-front/internal/testutil"
+)
+
+var _ = Describe("Encoder", func() {
+	var (
+		options   mtl.EncodeOptions
+		library   *mtl.Library
+		buffer    *bytes.Buffer
+		encodeErr error
+	)
+
+	decodeBuffer := func() *mtl.Library {
+		GinkgoHelper()
+		decoder := mtl.NewDecoder(mtl.DefaultLimits())
+		decodedLibrary, err := decoder.Decode(buffer)
+		Expect(err).ToNot(HaveOccurred())
+		return decodedLibrary
+	}
+
+	JustBeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder := mtl.NewEncoder(options)
+		encodeErr = encoder.Encode(buffer, library)
+	})
+
+	BeforeEach(func() {
+		options = mtl.DefaultEncodeOptions()
+
+		firstMaterial := mtl.DefaultMaterial()
+		firstMaterial.Name = "First"
+		firstMaterial.DiffuseColor = mtl.RGBColor{R: 0.5, G: 0.25, B: 0.0}
+		firstMaterial.SpecularExponent = 250.0
+		firstMaterial.Illumination = 2
+		firstMaterial.DiffuseTexture = "diffuse.png"
+
+		secondMaterial := mtl.DefaultMaterial()
+		secondMaterial.Name = "Second"
+
+		library = &mtl.Library{
+			Materials: []*mtl.Material{firstMaterial, secondMaterial},
+		}
+	})
+
+	It("should not have returned an error", func() {
+		Expect(encodeErr).ToNot(HaveOccurred())
+	})
+
+	It("should have written all values", func() {
+		Expect(buffer.String()).To(Equal(
+			"newmtl First\n" +
+				"Ka 1 1 1\n" +
+				"Kd 0.5 0.25 0\n" +
+				"Ks 0 0 0\n" +
+				"Ke 0 0 0\n" +
+				"Tf 1 1 1\n" +
+				"d 1\n" +
+				"Ns 250\n" +
+				"Ni 1\n" +
+				"sharpness 60\n" +
+				"illum 2\n" +
+				"map_Kd diffuse.png\n" +
+				"\n" +
+				"newmtl Second\n" +
+				"Ka 1 1 1\n" +
+				"Kd 1 1 1\n" +
+				"Ks 0 0 0\n" +
+				"Ke 0 0 0\n" +
+				"Tf 1 1 1\n" +
+				"d 1\n" +
+				"Ns 0\n" +
+				"Ni 1\n" +
+				"sharpness 60\n" +
+				"illum 0\n",
+		))
+	})
+
+	It("should be possible to decode the resource", func() {
+		Expect(decodeBuffer()).To(Equal(library))
+	})
+
+	When("defaults are omitted", func() {
+		BeforeEach(func() {
+			options.OmitDefaults = true
+		})
+
+		It("should have written only non-default values", func() {
+			Expect(buffer.String()).To(Equal(
+				"newmtl First\n" +
+					"Kd 0.5 0.25 0\n" +
+					"Ns 250\n" +
+					"illum 2\n" +
+					"map_Kd diffuse.png\n" +
+					"\n" +
+					"newmtl Second\n",
+			))
+		})
+
+		It("should be possible to decode the resource", func() {
+			Expect(decodeBuffer()).To(Equal(library))
+		})
+	})
+
+	When("textures have options", func() {
+		BeforeEach(func() {
+			material := library.Materials[0]
+			material.DiffuseTextureOptions.Clamp = true
+			material.DiffuseTextureOptions.Scale = mtl.TextureVector{U: 2.0, V: 2.0, W: 1.0}
+			material.DiffuseTextureOptions.Channel = "r"
+			material.DiffuseTextureOptions.BlendU = false
+		})
+
+		It("should have written the non-default options", func() {
+			Expect(buffer.String()).To(ContainSubstring(
+				"map_Kd -blendu off -clamp on -imfchan r -s 2 2 1 diffuse.png\n",
+			))
+		})
+
+		It("should be possible to decode the resource", func() {
+			Expect(decodeBuffer()).To(Equal(library))
+		})
+	})
+
+	When("material names contain spaces", func() {
+		BeforeEach(func() {
+			library.Materials[1].Name = "my mat"
+		})
+
+		It("should have written the full name", func() {
+			Expect(buffer.String()).To(ContainSubstring("newmtl my mat\n"))
+		})
+
+		It("should be possible to decode the resource", func() {
+			Expect(decodeBuffer()).To(Equal(library))
+		})
+	})
+
+	When("a decoded file is encoded", func() {
+		decodeTestFile := func(testFile string) *mtl.Library {
+			GinkgoHelper()
+			file, err := os.Open(filepath.Join("testdata", testFile))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			decoder := mtl.NewDecoder(mtl.DefaultLimits())
+			decodedLibrary, err := decoder.Decode(file)
+			Expect(err).ToNot(HaveOccurred())
+			return decodedLibrary
+		}
+
+		itShouldProduceAnEqualLibrary := func() {
+			GinkgoHelper()
+			It("should produce an equal library when decoded again", func() {
+				Expect(encodeErr).ToNot(HaveOccurred())
+				Expect(decodeBuffer()).To(Equal(library))
+			})
+		}
+
+		Context("basic file", func() {
+			BeforeEach(func() {
+				library = decodeTestFile("valid_basic.mtl")
+			})
+
+			itShouldProduceAnEqualLibrary()
+		})
+
+		Context("file with unknown commands", func() {
+			BeforeEach(func() {
+				library = decodeTestFile("valid_unknown_commands.mtl")
+			})
+
+			itShouldProduceAnEqualLibrary()
+		})
+
+		Context("file with spectral and xyz colors", func() {
+			BeforeEach(func() {
+				library = decodeTestFile("valid_alternative_colors.mtl")
+			})
+
+			itShouldProduceAnEqualLibrary()
+		})
+
+		Context("file with optical declarations", func() {
+			BeforeEach(func() {
+				library = decodeTestFile("valid_dissolve_conventions.mtl")
+			})
+
+			itShouldProduceAnEqualLibrary()
+		})
+
+		Context("file with displacement, decal, and reflection textures", func() {
+			BeforeEach(func() {
+				library = decodeTestFile("valid_extra_textures.mtl")
+			})
+
+			itShouldProduceAnEqualLibrary()
+		})
+
+		Context("file with PBR declarations", func() {
+			BeforeEach(func() {
+				library = decodeTestFile("valid_pbr.mtl")
+			})
+
+			itShouldProduceAnEqualLibrary()
+		})
+	})
+
+	When("the writer fails", func() {
+		var errStubbed = errors.New("stubbed to fail")
+
+		It("should have returned the writer error", func() {
+			err := mtl.NewEncoder(options).Encode(testutil.NewFailingWriter(errStubbed), library)
+			Expect(err).To(Equal(errStubbed))
+		})
+	})
+})
+
+var _ = Describe("EncodeOptions", func() {
+	var options mtl.EncodeOptions
+
+	Describe("DefaultEncodeOptions", func() {
+		BeforeEach(func() {
+			options = mtl.DefaultEncodeOptions()
+		})
+
+		Specify("defaults should not be omitted", func() {
+			Expect(options.OmitDefaults).To(BeFalse())
+		})
+	})
+})
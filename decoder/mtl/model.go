@@ -17,6 +17,140 @@ type RGBColor struct {
 	B float64
 }
 
+// XYZColor represents a color in the CIE XYZ
+// color space.
+type XYZColor struct {
+
+	// Specifies the X component of this color.
+	X float64
+
+	// Specifies the Y component of this color.
+	Y float64
+
+	// Specifies the Z component of this color.
+	Z float64
+}
+
+// LinearRGB converts this color to the linear sRGB color
+// space, using the D65 reference white.
+//
+// The resulting components are not clamped, so colors that
+// are outside of the sRGB gamut can have components that
+// are negative or larger than 1.0.
+func (c XYZColor) LinearRGB() RGBColor {
+	return RGBColor{
+		R: 3.2404542*c.X - 1.5371385*c.Y - 0.4985314*c.Z,
+		G: -0.9692660*c.X + 1.8760108*c.Y + 0.0415560*c.Z,
+		B: 0.0556434*c.X - 0.2040259*c.Y + 1.0572252*c.Z,
+	}
+}
+
+// SpectralColor represents a color that is described
+// by a spectral curve file.
+type SpectralColor struct {
+
+	// FilePath specifies the location of the spectral
+	// curve file (.rfl).
+	FilePath string
+
+	// Factor specifies the multiplier for the values in
+	// the spectral curve file.
+	Factor float64
+}
+
+// TextureVector holds the three components of a texture
+// option such as an offset or a scale.
+type TextureVector struct {
+
+	// U specifies the horizontal component.
+	U float64
+
+	// V specifies the vertical component.
+	V float64
+
+	// W specifies the depth component.
+	W float64
+}
+
+// TextureOptions holds the options that control how a
+// texture should be applied.
+type TextureOptions struct {
+
+	// BlendU specifies whether horizontal texture blending
+	// is enabled.
+	BlendU bool
+
+	// BlendV specifies whether vertical texture blending
+	// is enabled.
+	BlendV bool
+
+	// BumpMultiplier specifies the amount by which bump
+	// values should be multiplied.
+	BumpMultiplier float64
+
+	// Boost specifies the amount by which the sharpness of
+	// mip-mapped textures should be increased.
+	Boost float64
+
+	// ColorCorrection specifies whether color correction
+	// is enabled.
+	ColorCorrection bool
+
+	// Clamp specifies whether texture coordinates should be
+	// clamped to the range 0.0 to 1.0.
+	Clamp bool
+
+	// Channel specifies the channel of the texture that should
+	// be used to create a scalar or bump texture. It is one of
+	// `r`, `g`, `b`, `m`, `l`, or `z`, or the empty string if
+	// no channel was specified.
+	Channel string
+
+	// RangeBase specifies the value that should be added to
+	// the texture values.
+	RangeBase float64
+
+	// RangeGain specifies the range over which the texture
+	// values should be spread.
+	RangeGain float64
+
+	// Offset specifies the texture origin offset.
+	Offset TextureVector
+
+	// Scale specifies the texture scale.
+	Scale TextureVector
+
+	// Turbulence specifies the texture turbulence.
+	Turbulence TextureVector
+
+	// Resolution specifies the resolution of the texture that
+	// should be created. It is zero if it was not specified.
+	Resolution int64
+
+	// Type specifies the type of a reflection texture. It is
+	// one of `sphere`, `cube_top`, `cube_bottom`, `cube_front`,
+	// `cube_back`, `cube_left`, or `cube_right`, or the empty
+	// string if no type was specified.
+	Type string
+}
+
+// DefaultTextureOptions returns new TextureOptions which
+// are initialized with the default values of the MTL format.
+func DefaultTextureOptions() TextureOptions {
+	return TextureOptions{
+		BlendU:         true,
+		BlendV:         true,
+		BumpMultiplier: 1.0,
+		RangeBase:      0.0,
+		RangeGain:      1.0,
+		Scale: TextureVector{
+			U: 1.0,
+			V: 1.0,
+			W: 1.0,
+		},
+	}
+}
+
 // Material represents a material in a MTL
 // wavefront resource.
 //
@@ -30,22 +164,77 @@ type Material struct {
 	// when rendering objects.
 	AmbientColor RGBColor
 
+	// AmbientColorXYZ holds the original CIE XYZ value of the
+	// ambient color, if it was specified in that color space.
+	// In that case, AmbientColor holds the converted value.
+	AmbientColorXYZ *XYZColor
+
+	// AmbientColorSpectral holds the spectral curve of the
+	// ambient color, if it was specified as such. Since spectral
+	// curves cannot be converted without resolving the
+	// file, AmbientColor keeps its previous value.
+	AmbientColorSpectral *SpectralColor
+
 	// DiffuseColor holds the diffuse color to be used
 	// when rendering objects.
 	DiffuseColor RGBColor
 
+	// DiffuseColorXYZ holds the original CIE XYZ value of the
+	// diffuse color, if it was specified in that color space.
+	// In that case, DiffuseColor holds the converted value.
+	DiffuseColorXYZ *XYZColor
+
+	// DiffuseColorSpectral holds the spectral curve of the
+	// diffuse color, if it was specified as such. Since spectral
+	// curves cannot be converted without resolving the
+	// file, DiffuseColor keeps its previous value.
+	DiffuseColorSpectral *SpectralColor
+
 	// SpecularColor holds the specular color to be used
 	// when rendering objects.
 	SpecularColor RGBColor
 
+	// SpecularColorXYZ holds the original CIE XYZ value of the
+	// specular color, if it was specified in that color space.
+	// In that case, SpecularColor holds the converted value.
+	SpecularColorXYZ *XYZColor
+
+	// SpecularColorSpectral holds the spectral curve of the
+	// specular color, if it was specified as such. Since spectral
+	// curves cannot be converted without resolving the
+	// file, SpecularColor keeps its previous value.
+	SpecularColorSpectral *SpectralColor
+
 	// EmissiveColor holds the emissive color to be used
 	// when rendering objects.
 	EmissiveColor RGBColor
 
+	// EmissiveColorXYZ holds the original CIE XYZ value of the
+	// emissive color, if it was specified in that color space.
+	// In that case, EmissiveColor holds the converted value.
+	EmissiveColorXYZ *XYZColor
+
+	// EmissiveColorSpectral holds the spectral curve of the
+	// emissive color, if it was specified as such. Since spectral
+	// curves cannot be converted without resolving the
+	// file, EmissiveColor keeps its previous value.
+	EmissiveColorSpectral *SpectralColor
+
 	// TransmissionFilter holds the filter to be used on
 	// colors when rendering objects.
 	TransmissionFilter RGBColor
 
+	// TransmissionFilterXYZ holds the original CIE XYZ value of the
+	// transmission filter, if it was specified in that color space.
+	// In that case, TransmissionFilter holds the converted value.
+	TransmissionFilterXYZ *XYZColor
+
+	// TransmissionFilterSpectral holds the spectral curve of the
+	// transmission filter, if it was specified as such. Since spectral
+	// curves cannot be converted without resolving the
+	// file, TransmissionFilter keeps its previous value.
+	TransmissionFilterSpectral *SpectralColor
+
 	// SpecularExponent defines the specular exponent for
 	// this material.
 	//
@@ -61,6 +250,28 @@ type Material struct {
 	// and 1.0 (opaque).
 	Dissolve float64
 
+	// DissolveHalo specifies whether the dissolve depends
+	// on the surface orientation relative to the viewer.
+	//
+	// If true, then the dissolve is equal to Dissolve at
+	// surfaces that face the viewer and becomes more opaque
+	// towards the edges of the object.
+	DissolveHalo bool
+
+	// OpticalDensity defines the index of refraction for
+	// this material.
+	//
+	// The value ranges between 0.001 and 10.0, where 1.0
+	// means that light does not bend as it passes through
+	// objects.
+	OpticalDensity float64
+
+	// Sharpness defines the sharpness of the reflections
+	// from the local reflection map.
+	//
+	// The value ranges between 0.0 and 1000.0.
+	Sharpness float64
+
 	// Illumination defines the illumination model to be used when
 	// rendering objects.
 	//
@@ -79,6 +290,143 @@ type Material struct {
 	//	10. Casts shadows onto invisible surfaces
 	Illumination int64
 
+	// DisplacementTexture defines the location of the displacement
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// displacement texture provided.
+	DisplacementTexture string
+
+	// DisplacementTextureOptions holds the options that control how the
+	// displacement texture should be applied.
+	DisplacementTextureOptions TextureOptions
+
+	// DecalTexture defines the location of the decal
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// decal texture provided.
+	DecalTexture string
+
+	// DecalTextureOptions holds the options that control how the
+	// decal texture should be applied.
+	DecalTextureOptions TextureOptions
+
+	// ReflectionTexture defines the location of the reflection
+	// texture to be used when rendering objects.
+	//
+	// This holds spherical reflection maps, as well as
+	// reflection maps that do not specify their type.
+	//
+	// If this value is the empty string, then there is no
+	// reflection texture provided.
+	ReflectionTexture string
+
+	// ReflectionTextureOptions holds the options that control how the
+	// reflection texture should be applied.
+	ReflectionTextureOptions TextureOptions
+
+	// ReflectionCubeTopTexture defines the location of the cube top reflection
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// cube top reflection texture provided.
+	ReflectionCubeTopTexture string
+
+	// ReflectionCubeTopTextureOptions holds the options that control how the
+	// cube top reflection texture should be applied.
+	ReflectionCubeTopTextureOptions TextureOptions
+
+	// ReflectionCubeBottomTexture defines the location of the cube bottom reflection
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// cube bottom reflection texture provided.
+	ReflectionCubeBottomTexture string
+
+	// ReflectionCubeBottomTextureOptions holds the options that control how the
+	// cube bottom reflection texture should be applied.
+	ReflectionCubeBottomTextureOptions TextureOptions
+
+	// ReflectionCubeFrontTexture defines the location of the cube front reflection
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// cube front reflection texture provided.
+	ReflectionCubeFrontTexture string
+
+	// ReflectionCubeFrontTextureOptions holds the options that control how the
+	// cube front reflection texture should be applied.
+	ReflectionCubeFrontTextureOptions TextureOptions
+
+	// ReflectionCubeBackTexture defines the location of the cube back reflection
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// cube back reflection texture provided.
+	ReflectionCubeBackTexture string
+
+	// ReflectionCubeBackTextureOptions holds the options that control how the
+	// cube back reflection texture should be applied.
+	ReflectionCubeBackTextureOptions TextureOptions
+
+	// ReflectionCubeLeftTexture defines the location of the cube left reflection
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// cube left reflection texture provided.
+	ReflectionCubeLeftTexture string
+
+	// ReflectionCubeLeftTextureOptions holds the options that control how the
+	// cube left reflection texture should be applied.
+	ReflectionCubeLeftTextureOptions TextureOptions
+
+	// ReflectionCubeRightTexture defines the location of the cube right reflection
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// cube right reflection texture provided.
+	ReflectionCubeRightTexture string
+
+	// ReflectionCubeRightTextureOptions holds the options that control how the
+	// cube right reflection texture should be applied.
+	ReflectionCubeRightTextureOptions TextureOptions
+
+	// Roughness defines the roughness of this material when
+	// using physically based rendering (PBR).
+	//
+	// The value should range between 0.0 (smooth) and 1.0 (rough).
+	Roughness float64
+
+	// Metallic defines how metallic this material is when using
+	// physically based rendering (PBR).
+	//
+	// The value should range between 0.0 (dielectric) and 1.0
+	// (metal).
+	Metallic float64
+
+	// Sheen defines the amount of sheen of this material when
+	// using physically based rendering (PBR).
+	Sheen float64
+
+	// ClearcoatThickness defines the thickness of the clearcoat
+	// layer of this material when using physically based
+	// rendering (PBR).
+	ClearcoatThickness float64
+
+	// ClearcoatRoughness defines the roughness of the clearcoat
+	// layer of this material when using physically based
+	// rendering (PBR).
+	ClearcoatRoughness float64
+
+	// Anisotropy defines the amount of anisotropy of the
+	// specular reflection of this material.
+	Anisotropy float64
+
+	// AnisotropyRotation defines the rotation of the anisotropy
+	// of the specular reflection of this material.
+	AnisotropyRotation float64
+
 	// AmbientTexture defines the location of the ambient
 	// texture to be used when rendering objects.
 	//
@@ -86,6 +434,10 @@ type Material struct {
 	// ambient texture provided.
 	AmbientTexture string
 
+	// AmbientTextureOptions holds the options that control how the
+	// ambient texture should be applied.
+	AmbientTextureOptions TextureOptions
+
 	// DiffuseTexture defines the location of the diffuse
 	// texture to be used when rendering objects.
 	//
@@ -93,6 +445,10 @@ type Material struct {
 	// diffuse texture provided.
 	DiffuseTexture string
 
+	// DiffuseTextureOptions holds the options that control how the
+	// diffuse texture should be applied.
+	DiffuseTextureOptions TextureOptions
+
 	// SpecularTexture defines the location of the specular
 	// texture to be used when rendering objects.
 	//
@@ -100,6 +456,10 @@ type Material struct {
 	// specular texture provided.
 	SpecularTexture string
 
+	// SpecularTextureOptions holds the options that control how the
+	// specular texture should be applied.
+	SpecularTextureOptions TextureOptions
+
 	// EmissiveTexture defines the location of the emissive
 	// texture to be used when rendering objects.
 	//
@@ -107,6 +467,10 @@ type Material struct {
 	// emissive texture provided.
 	EmissiveTexture string
 
+	// EmissiveTextureOptions holds the options that control how the
+	// emissive texture should be applied.
+	EmissiveTextureOptions TextureOptions
+
 	// SpecularExponentTexture defines the location of the specular
 	// exponent texture to be used when rendering objects.
 	//
@@ -114,6 +478,10 @@ type Material struct {
 	// specular exponent texture provided.
 	SpecularExponentTexture string
 
+	// SpecularExponentTextureOptions holds the options that control how the
+	// specular exponent texture should be applied.
+	SpecularExponentTextureOptions TextureOptions
+
 	// DissolveTexture defines the location of the dissolve
 	// texture to be used when rendering objects.
 	//
@@ -121,12 +489,84 @@ type Material struct {
 	// dissolve texture provided.
 	DissolveTexture string
 
+	// DissolveTextureOptions holds the options that control how the
+	// dissolve texture should be applied.
+	DissolveTextureOptions TextureOptions
+
 	// BumpTexture defines the location of the bump
 	// texture to be used when rendering objects.
 	//
 	// If this value is the empty string, then there is no
 	// Bump texture provided.
 	BumpTexture string
+
+	// BumpTextureOptions holds the options that control how the
+	// bump texture should be applied.
+	BumpTextureOptions TextureOptions
+
+	// RoughnessTexture defines the location of the roughness
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// roughness texture provided.
+	RoughnessTexture string
+
+	// RoughnessTextureOptions holds the options that control how the
+	// roughness texture should be applied.
+	RoughnessTextureOptions TextureOptions
+
+	// MetallicTexture defines the location of the metallic
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// metallic texture provided.
+	MetallicTexture string
+
+	// MetallicTextureOptions holds the options that control how the
+	// metallic texture should be applied.
+	MetallicTextureOptions TextureOptions
+
+	// SheenTexture defines the location of the sheen
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// sheen texture provided.
+	SheenTexture string
+
+	// SheenTextureOptions holds the options that control how the
+	// sheen texture should be applied.
+	SheenTextureOptions TextureOptions
+
+	// RMATexture defines the location of the RMA
+	// texture to be used when rendering objects.
+	//
+	// The texture holds roughness in its red channel, metallic
+	// in its green channel, and ambient occlusion in its blue
+	// channel.
+	//
+	// If this value is the empty string, then there is no
+	// RMA texture provided.
+	RMATexture string
+
+	// RMATextureOptions holds the options that control how the
+	// RMA texture should be applied.
+	RMATextureOptions TextureOptions
+
+	// NormalTexture defines the location of the normal
+	// texture to be used when rendering objects.
+	//
+	// If this value is the empty string, then there is no
+	// normal texture provided.
+	NormalTexture string
+
+	// NormalTextureOptions holds the options that control how the
+	// normal texture should be applied.
+	NormalTextureOptions TextureOptions
+
+	// UnknownCommands holds a list of all the commands of
+	// this material that are not supported by the decoder,
+	// in the order in which they were declared.
+	UnknownCommands []UnknownCommand
 }
 
 // DefaultMaterial returns a new Material which is
@@ -143,12 +583,35 @@ func DefaultMaterial() *Material {
 			G: 1.0,
 			B: 1.0,
 		},
-		Dissolve: 1.0,
+		Dissolve:       1.0,
+		OpticalDensity: 1.0,
+		Sharpness:      60.0,
 		TransmissionFilter: RGBColor{
 			R: 1.0,
 			G: 1.0,
 			B: 1.0,
 		},
+		AmbientTextureOptions:              DefaultTextureOptions(),
+		DiffuseTextureOptions:              DefaultTextureOptions(),
+		SpecularTextureOptions:             DefaultTextureOptions(),
+		EmissiveTextureOptions:             DefaultTextureOptions(),
+		SpecularExponentTextureOptions:     DefaultTextureOptions(),
+		DissolveTextureOptions:             DefaultTextureOptions(),
+		BumpTextureOptions:                 DefaultTextureOptions(),
+		RoughnessTextureOptions:            DefaultTextureOptions(),
+		MetallicTextureOptions:             DefaultTextureOptions(),
+		SheenTextureOptions:                DefaultTextureOptions(),
+		RMATextureOptions:                  DefaultTextureOptions(),
+		NormalTextureOptions:               DefaultTextureOptions(),
+		DisplacementTextureOptions:         DefaultTextureOptions(),
+		DecalTextureOptions:                DefaultTextureOptions(),
+		ReflectionTextureOptions:           DefaultTextureOptions(),
+		ReflectionCubeTopTextureOptions:    DefaultTextureOptions(),
+		ReflectionCubeBottomTextureOptions: DefaultTextureOptions(),
+		ReflectionCubeFrontTextureOptions:  DefaultTextureOptions(),
+		ReflectionCubeBackTextureOptions:   DefaultTextureOptions(),
+		ReflectionCubeLeftTextureOptions:   DefaultTextureOptions(),
+		ReflectionCubeRightTextureOptions:  DefaultTextureOptions(),
 	}
 }
 
@@ -162,6 +625,11 @@ type Library struct {
 	// Materials contains a list of all the materials that
 	// were defined in the given library.
 	Materials []*Material
+
+	// UnknownCommands holds a list of all the commands that
+	// are not supported by the decoder and that were declared
+	// before the first material.
+	UnknownCommands []UnknownCommand
 }
 
 // FindMaterial finds a material in the given Library
@@ -174,3 +642,14 @@ func (l *Library) FindMaterial(name string) (*Material, bool) {
 	}
 	return nil, false
 }
+
+// UnknownCommand represents a command that is not supported
+// by the decoder (e.g. a vendor-specific extension).
+type UnknownCommand struct {
+
+	// Name holds the name of the command.
+	Name string
+
+	// Params holds the raw parameters of the command.
+	Params []string
+}
@@ -58,6 +58,66 @@ var _ = Describe("Material", func() {
 				B: 1.0,
 			}))
 		})
+
+		It("should have an optical density of 1.0", func() {
+			Expect(material.OpticalDensity).To(Equal(1.0))
+		})
+
+		It("should have a sharpness of 60.0", func() {
+			Expect(material.Sharpness).To(Equal(60.0))
+		})
+
+		It("should have default texture options", func() {
+			Expect(material.DiffuseTextureOptions).To(Equal(mtl.DefaultTextureOptions()))
+			Expect(material.BumpTextureOptions).To(Equal(mtl.DefaultTextureOptions()))
+		})
+	})
+})
+
+var _ = Describe("XYZColor", func() {
+	Describe("LinearRGB", func() {
+		It("should convert the D65 white point to white", func() {
+			color := mtl.XYZColor{X: 0.95047, Y: 1.0, Z: 1.08883}.LinearRGB()
+			Expect(color.R).To(BeNumerically("~", 1.0, 0.001))
+			Expect(color.G).To(BeNumerically("~", 1.0, 0.001))
+			Expect(color.B).To(BeNumerically("~", 1.0, 0.001))
+		})
+
+		It("should convert black to black", func() {
+			Expect(mtl.XYZColor{}.LinearRGB()).To(Equal(mtl.RGBColor{}))
+		})
+	})
+})
+
+var _ = Describe("TextureOptions", func() {
+	var options mtl.TextureOptions
+
+	Describe("DefaultTextureOptions", func() {
+		BeforeEach(func() {
+			options = mtl.DefaultTextureOptions()
+		})
+
+		It("should have blending enabled", func() {
+			Expect(options.BlendU).To(BeTrue())
+			Expect(options.BlendV).To(BeTrue())
+		})
+
+		It("should have a bump multiplier of 1.0", func() {
+			Expect(options.BumpMultiplier).To(Equal(1.0))
+		})
+
+		It("should have an identity value range", func() {
+			Expect(options.RangeBase).To(Equal(0.0))
+			Expect(options.RangeGain).To(Equal(1.0))
+		})
+
+		It("should have a unit scale", func() {
+			Expect(options.Scale).To(Equal(mtl.TextureVector{
+				U: 1.0,
+				V: 1.0,
+				W: 1.0,
+			}))
+		})
 	})
 })
 
@@ -1,8 +1,12 @@
 package obj
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/mokiat/go-data-front/common"
 	objscan "github.com/mokiat/go-data-front/scanner/obj"
@@ -31,6 +35,12 @@ type DecodeLimits struct {
 	// that can be parsed before an error is thrown.
 	MaxObjectCount int
 
+	// MaxGroupCount specifies the maximum number of distinct
+	// groups that can be parsed before an error is thrown.
+	//
+	// A value of zero results in the default limit being used.
+	MaxGroupCount int
+
 	// MaxFaceCount specifies the maximum number of faces
 	// that can be parsed per mesh before an error is thrown.
 	MaxFaceCount int
@@ -39,6 +49,24 @@ type DecodeLimits struct {
 	// references that a given face can have.
 	MaxReferenceCount int
 
+	// MaxLineCount specifies the maximum number of lines
+	// that can be parsed per mesh before an error is thrown.
+	//
+	// A value of zero results in the default limit being used.
+	MaxLineCount int
+
+	// MaxLineReferenceCount specifies the maximum number of
+	// vertex references that a given line can have.
+	//
+	// A value of zero results in the default limit being used.
+	MaxLineReferenceCount int
+
+	// MaxPointCount specifies the maximum number of points
+	// that can be parsed per mesh before an error is thrown.
+	//
+	// A value of zero results in the default limit being used.
+	MaxPointCount int
+
 	// MaxMaterialLibraryCount specifies the maximum number of
 	// material library references that can be parsed before
 	// an error is thrown.
@@ -48,6 +76,21 @@ type DecodeLimits struct {
 	// material references that can be parsed per object before
 	// an error is thrown.
 	MaxMaterialReferenceCount int
+
+	// MaxUnknownCommandCount specifies the maximum number of
+	// unsupported commands that are collected. Any further
+	// unsupported commands are dropped.
+	//
+	// A value of zero results in the default limit being used.
+	MaxUnknownCommandCount int
+
+	// MaxLineLength specifies the maximum length in bytes that
+	// a logical line can have before an error is thrown.
+	//
+	// A value of zero results in common.DefaultMaxLineLength being
+	// used. A value of common.UnlimitedLineLength disables this
+	// check.
+	MaxLineLength int
 }
 
 // DefaultLimits returns some default DecodeLimits.
@@ -58,10 +101,106 @@ func DefaultLimits() DecodeLimits {
 		MaxTexCoordCount:          65536,
 		MaxNormalCount:            65536,
 		MaxObjectCount:            1024,
+		MaxGroupCount:             1024,
 		MaxFaceCount:              65536,
 		MaxReferenceCount:         16,
+		MaxLineCount:              65536,
+		MaxLineReferenceCount:     1024,
+		MaxPointCount:             65536,
 		MaxMaterialReferenceCount: 64,
 		MaxMaterialLibraryCount:   32,
+		MaxUnknownCommandCount:    1024,
+		MaxLineLength:             common.DefaultMaxLineLength,
+	}
+}
+
+// withDefaults returns a copy of the DecodeLimits in which the
+// limits that are zero are replaced by their default values, for
+// those limits where zero is not a meaningful restriction.
+func (l DecodeLimits) withDefaults() DecodeLimits {
+	defaults := DefaultLimits()
+	if l.MaxGroupCount == 0 {
+		l.MaxGroupCount = defaults.MaxGroupCount
+	}
+	if l.MaxLineCount == 0 {
+		l.MaxLineCount = defaults.MaxLineCount
+	}
+	if l.MaxLineReferenceCount == 0 {
+		l.MaxLineReferenceCount = defaults.MaxLineReferenceCount
+	}
+	if l.MaxPointCount == 0 {
+		l.MaxPointCount = defaults.MaxPointCount
+	}
+	if l.MaxUnknownCommandCount == 0 {
+		l.MaxUnknownCommandCount = defaults.MaxUnknownCommandCount
+	}
+	return l
+}
+
+// DecodeOptions specifies how an OBJ resource should be
+// mapped to the object model.
+type DecodeOptions struct {
+
+	// GroupsAsObjects specifies whether group declarations
+	// should additionally be treated as object declarations.
+	//
+	// This is useful for resources that use groups instead of
+	// objects to separate their parts. A group declaration with
+	// multiple names results in an object that has all names
+	// separated by a space. Faces continue to be assigned to
+	// their Groups as well.
+	GroupsAsObjects bool
+
+	// SingleMaterialLibraryPath specifies whether a material
+	// library declaration should be treated as a single path
+	// that may contain spaces, instead of as a list of paths.
+	SingleMaterialLibraryPath bool
+
+	// Mode specifies how problems in the resource are handled.
+	//
+	// In common.ScanModeLenient, malformed lines and invalid
+	// constructs (e.g. a face with less than three vertices)
+	// are skipped and reported through the WarningHandler.
+	Mode common.ScanMode
+
+	// WarningHandler, if specified, is called for each problem
+	// that is skipped in common.ScanModeLenient.
+	WarningHandler common.WarningHandler
+
+	// ContextCheckInterval specifies the number of logical lines
+	// after which the context.Context passed to DecodeContext is
+	// checked for cancellation. Values lower than 1 result in a
+	// check before every line.
+	ContextCheckInterval int
+
+	// ProgressHandler, if specified, is called periodically
+	// while decoding, as well as once decoding has completed.
+	ProgressHandler common.ProgressHandler
+
+	// ProgressInterval specifies the minimum amount of time
+	// between two calls to the ProgressHandler. A value of zero
+	// results in common.DefaultProgressInterval being used,
+	// while negative values result in a call after every line.
+	ProgressInterval time.Duration
+
+	// TotalBytes specifies the size of the resource in bytes, if
+	// it is known. Otherwise, the size is determined when the
+	// io.Reader is an io.Seeker.
+	TotalBytes int64
+}
+
+// DefaultDecodeOptions returns some default DecodeOptions.
+// Users can take the result and modify specific parameters.
+func DefaultDecodeOptions() DecodeOptions {
+	return DecodeOptions{
+		GroupsAsObjects:           false,
+		SingleMaterialLibraryPath: false,
+		Mode:                      common.ScanModeStandard,
+		WarningHandler:            nil,
+		ContextCheckInterval:      common.DefaultContextCheckInterval,
+		ProgressHandler:           nil,
+		ProgressInterval:          common.DefaultProgressInterval,
+		TotalBytes:                0,
 	}
 }
 
@@ -73,49 +212,136 @@ type Decoder interface {
 	// through the io.Reader, into a Library model.
 	//
 	// If decoding fails for some reason, an error is returned.
+	// Invalid constructs are reported through a *common.ParseError
+	// that points to the offending element.
 	Decode(io.Reader) (*Model, error)
+
+	// DecodeContext is like Decode but stops decoding once the
+	// specified context.Context is done, in which case the error
+	// of the context is returned.
+	DecodeContext(context.Context, io.Reader) (*Model, error)
 }
 
 // NewDecoder creates a new Decoder instance with the
 // specified DecodeLimits.
+//
+// The decoder uses the options returned by DefaultDecodeOptions.
 func NewDecoder(limits DecodeLimits) Decoder {
+	return NewDecoderWithOptions(limits, DefaultDecodeOptions())
+}
+
+// NewDecoderWithOptions creates a new Decoder instance with the
+// specified DecodeLimits and DecodeOptions.
+func NewDecoderWithOptions(limits DecodeLimits, options DecodeOptions) Decoder {
+	limits = limits.withDefaults()
 	return &decoder{
 		limits:  &limits,
-		scanner: objscan.NewScanner(),
+		options: &options,
 	}
 }
 
 type decoder struct {
 	limits  *DecodeLimits
-	scanner common.Scanner
+	options *DecodeOptions
 }
 
 func (d *decoder) Decode(reader io.Reader) (*Model, error) {
-	context := newDecodeContext(d.limits)
-	err := d.scanner.Scan(reader, context.HandleEvent)
+	return d.DecodeContext(context.Background(), reader)
+}
+
+func (d *decoder) DecodeContext(ctx context.Context, reader io.Reader) (*Model, error) {
+	scanner := objscan.NewScannerWithOptions(common.ScanOptions{
+		MaxLineLength:             d.limits.MaxLineLength,
+		SingleMaterialLibraryPath: d.options.SingleMaterialLibraryPath,
+		Mode:                      d.options.Mode,
+		WarningHandler:            d.options.WarningHandler,
+		ContextCheckInterval:      d.options.ContextCheckInterval,
+		ProgressHandler:           d.options.ProgressHandler,
+		ProgressInterval:          d.options.ProgressInterval,
+		TotalBytes:                d.options.TotalBytes,
+	}).(common.PositionedScanner)
+	decodeCtx := newDecodeContext(d.limits, d.options)
+	err := scanner.ScanPositioned(ctx, reader, func(event common.Event, source common.EventSource) error {
+		err := decodeCtx.HandleEvent(event)
+		if err == nil || d.skipInvalidConstruct(source, err) {
+			return nil
+		}
+		return d.locateInvalidConstruct(source, err)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return context.Model(), nil
+	return decodeCtx.Model(), nil
 }
 
-func newDecodeContext(limits *DecodeLimits) *decodeContext {
+// skipInvalidConstruct reports the specified error as a warning and
+// returns true, if the decoder is lenient and the error was caused
+// by an invalid construct.
+func (d *decoder) skipInvalidConstruct(source common.EventSource, err error) bool {
+	if d.options.Mode != common.ScanModeLenient || !errors.Is(err, common.ErrInvalid) {
+		return false
+	}
+	if d.options.WarningHandler != nil {
+		d.options.WarningHandler(common.Warning{
+			Line:    source.Line,
+			Column:  source.Column,
+			Command: source.Command,
+			Err:     err,
+		})
+	}
+	return true
+}
+
+// locateInvalidConstruct wraps the specified error in a
+// *common.ParseError that points to the specified source, if
+// the error was caused by an invalid construct.
+func (d *decoder) locateInvalidConstruct(source common.EventSource, err error) error {
+	if !errors.Is(err, common.ErrInvalid) {
+		return err
+	}
+	return &common.ParseError{
+		Line:    source.Line,
+		Column:  source.Column,
+		Command: source.Command,
+		Err:     err,
+	}
+}
+
+func newDecodeContext(limits *DecodeLimits, options *DecodeOptions) *decodeContext {
 	return &decodeContext{
 		limits:        limits,
+		options:       options,
 		model:         new(Model),
 		currentObject: nil,
 		currentMesh:   nil,
 		currentFace:   nil,
+		currentLine:   nil,
 	}
 }
 
 type decodeContext struct {
 	limits           *DecodeLimits
+	options          *DecodeOptions
 	model            *Model
 	currentObject    *Object
+	currentGroups    []*Group
+	currentSmoothing int64
 	currentMesh      *Mesh
 	currentFace      *Face
+	currentLine      *Line
 	currentReference *Reference
+
+	// currentReferences points to the list of the element
+	// that is being scanned, which will receive references.
+	currentReferences     *[]Reference
+	currentReferenceLimit int
+	currentReferenceError string
+
+	// currentElementValid tracks whether an invalid reference
+	// was skipped in lenient mode, in which case the element
+	// is not added.
+	currentElementValid bool
+	invalidReferenceErr error
 }
 
 func (c *decodeContext) Model() *Model {
@@ -134,12 +360,24 @@ func (c *decodeContext) HandleEvent(event common.Event) error {
 		return c.handleTexCoord(actual)
 	case objscan.ObjectEvent:
 		return c.handleObject(actual)
+	case objscan.GroupEvent:
+		return c.handleGroup(actual)
+	case objscan.SmoothingGroupEvent:
+		return c.handleSmoothingGroup(actual)
 	case objscan.MaterialReferenceEvent:
 		return c.handleMaterialReference(actual)
 	case objscan.FaceStartEvent:
 		return c.handleFaceStart()
 	case objscan.FaceEndEvent:
 		return c.handleFaceEnd()
+	case objscan.LineStartEvent:
+		return c.handleLineStart()
+	case objscan.LineEndEvent:
+		return c.handleLineEnd()
+	case objscan.PointStartEvent:
+		return c.handlePointStart()
+	case objscan.PointEndEvent:
+		return c.handlePointEnd()
 	case objscan.ReferenceSetStartEvent:
 		return c.handleReferencesStart()
 	case objscan.ReferenceSetEndEvent:
@@ -150,6 +388,8 @@ func (c *decodeContext) HandleEvent(event common.Event) error {
 		return c.handleTexCoordReference(actual)
 	case objscan.NormalReferenceEvent:
 		return c.handleNormalReference(actual)
+	case common.UnknownCommandEvent:
+		return c.handleUnknownCommand(actual)
 	}
 	return nil
 }
@@ -171,6 +411,11 @@ func (c *decodeContext) handleVertex(event objscan.VertexEvent) error {
 		Y: event.Y,
 		Z: event.Z,
 		W: event.W,
+
+		R:        event.R,
+		G:        event.G,
+		B:        event.B,
+		HasColor: event.HasColor,
 	})
 	return nil
 }
@@ -210,6 +455,49 @@ func (c *decodeContext) handleObject(event objscan.ObjectEvent) error {
 	return nil
 }
 
+func (c *decodeContext) handleGroup(event objscan.GroupEvent) error {
+	groups := make([]*Group, len(event.GroupNames))
+	for i, name := range event.GroupNames {
+		group, found := c.model.FindGroup(name)
+		if !found {
+			if len(c.model.Groups) >= c.limits.MaxGroupCount {
+				return fmt.Errorf("%w: maximum number of groups reached", common.ErrLimitsExceeded)
+			}
+			group = &Group{
+				Name: name,
+			}
+			c.model.Groups = append(c.model.Groups, group)
+		}
+		groups[i] = group
+	}
+	c.currentGroups = groups
+
+	if c.options.GroupsAsObjects {
+		return c.handleGroupObject(strings.Join(event.GroupNames, " "))
+	}
+	return nil
+}
+
+func (c *decodeContext) handleGroupObject(name string) error {
+	c.currentMesh = nil
+	if object, found := c.model.FindObject(name); found {
+		c.currentObject = object
+		return nil
+	}
+	if len(c.model.Objects) >= c.limits.MaxObjectCount {
+		return fmt.Errorf("%w: maximum number of objects reached", common.ErrLimitsExceeded)
+	}
+	c.currentObject = new(Object)
+	c.currentObject.Name = name
+	c.model.Objects = append(c.model.Objects, c.currentObject)
+	return nil
+}
+
+func (c *decodeContext) handleSmoothingGroup(event objscan.SmoothingGroupEvent) error {
+	c.currentSmoothing = event.GroupNumber
+	return nil
+}
+
 func (c *decodeContext) handleMaterialReference(event objscan.MaterialReferenceEvent) error {
 	c.assureCurrentObject()
 	mesh, found := c.currentObject.FindMesh(event.MaterialName)
@@ -231,11 +519,18 @@ func (c *decodeContext) handleFaceStart() error {
 	if len(c.currentMesh.Faces) >= c.limits.MaxFaceCount {
 		return fmt.Errorf("%w: maximum number of faces reached", common.ErrLimitsExceeded)
 	}
-	c.currentFace = new(Face)
+	c.currentFace = &Face{
+		Groups:         c.currentGroups,
+		SmoothingGroup: c.currentSmoothing,
+	}
+	c.startReferences(&c.currentFace.References, c.limits.MaxReferenceCount, "maximum number of vertex references reached")
 	return nil
 }
 
 func (c *decodeContext) handleFaceEnd() error {
+	if !c.currentElementValid {
+		return nil
+	}
 	if len(c.currentFace.References) < 3 {
 		return fmt.Errorf("%w: face needs to have at least three vertices", common.ErrInvalid)
 	}
@@ -243,46 +538,116 @@ func (c *decodeContext) handleFaceEnd() error {
 	return nil
 }
 
+func (c *decodeContext) handleLineStart() error {
+	c.assureCurrentMesh()
+	if len(c.currentMesh.Lines) >= c.limits.MaxLineCount {
+		return fmt.Errorf("%w: maximum number of lines reached", common.ErrLimitsExceeded)
+	}
+	c.currentLine = &Line{}
+	c.startReferences(&c.currentLine.References, c.limits.MaxLineReferenceCount, "maximum number of line vertex references reached")
+	return nil
+}
+
+func (c *decodeContext) handleLineEnd() error {
+	if !c.currentElementValid {
+		return nil
+	}
+	if len(c.currentLine.References) < 2 {
+		return fmt.Errorf("%w: line needs to have at least two vertices", common.ErrInvalid)
+	}
+	c.currentMesh.Lines = append(c.currentMesh.Lines, c.currentLine)
+	return nil
+}
+
+func (c *decodeContext) handlePointStart() error {
+	c.assureCurrentMesh()
+	// Each reference set in a point declaration is a separate
+	// point, so they are appended to the mesh directly.
+	c.startReferences(&c.currentMesh.Points, c.limits.MaxPointCount, "maximum number of points reached")
+	return nil
+}
+
+func (c *decodeContext) handlePointEnd() error {
+	return nil
+}
+
+func (c *decodeContext) startReferences(references *[]Reference, limit int, limitError string) {
+	c.currentReferences = references
+	c.currentReferenceLimit = limit
+	c.currentReferenceError = limitError
+	c.currentElementValid = true
+}
+
 func (c *decodeContext) handleReferencesStart() error {
-	if len(c.currentFace.References) >= c.limits.MaxReferenceCount {
-		return fmt.Errorf("%w: maximum number of vertex references reached", common.ErrLimitsExceeded)
+	if len(*c.currentReferences) >= c.currentReferenceLimit {
+		return fmt.Errorf("%w: %s", common.ErrLimitsExceeded, c.currentReferenceError)
 	}
 	c.currentReference = &Reference{
 		TexCoordIndex: UndefinedIndex,
 		NormalIndex:   UndefinedIndex,
 	}
+	c.invalidReferenceErr = nil
 	return nil
 }
 
 func (c *decodeContext) handleReferencesEnd() error {
-	c.currentFace.References = append(c.currentFace.References, *c.currentReference)
+	if c.invalidReferenceErr != nil {
+		c.currentElementValid = false
+		return c.invalidReferenceErr
+	}
+	*c.currentReferences = append(*c.currentReferences, *c.currentReference)
 	return nil
 }
 
 func (c *decodeContext) handleVertexReference(event objscan.VertexReferenceEvent) error {
-	if event.VertexIndex > 0 {
-		c.currentReference.VertexIndex = event.VertexIndex - 1
-	} else {
-		c.currentReference.VertexIndex = int64(len(c.model.Vertices)) + event.VertexIndex
-	}
+	c.currentReference.VertexIndex = c.resolveReference(event.VertexIndex, len(c.model.Vertices), "vertex")
 	return nil
 }
 
 func (c *decodeContext) handleTexCoordReference(event objscan.TexCoordReferenceEvent) error {
-	if event.TexCoordIndex > 0 {
-		c.currentReference.TexCoordIndex = event.TexCoordIndex - 1
-	} else {
-		c.currentReference.TexCoordIndex = int64(len(c.model.TexCoords)) + event.TexCoordIndex
-	}
+	c.currentReference.TexCoordIndex = c.resolveReference(event.TexCoordIndex, len(c.model.TexCoords), "texture coordinate")
 	return nil
 }
 
 func (c *decodeContext) handleNormalReference(event objscan.NormalReferenceEvent) error {
-	if event.NormalIndex > 0 {
-		c.currentReference.NormalIndex = event.NormalIndex - 1
-	} else {
-		c.currentReference.NormalIndex = int64(len(c.model.Normals)) + event.NormalIndex
+	c.currentReference.NormalIndex = c.resolveReference(event.NormalIndex, len(c.model.Normals), "normal")
+	return nil
+}
+
+// resolveReference converts the specified 1-based or relative
+// (negative) index into a 0-based one and validates it against
+// the number of items that have been declared so far.
+//
+// Invalid indices are reported once the reference set ends,
+// which is when the reference is added to its element.
+func (c *decodeContext) resolveReference(index int64, count int, name string) int64 {
+	resolved := index - 1
+	if index < 0 {
+		resolved = int64(count) + index
 	}
+	var err error
+	switch {
+	case index == 0:
+		err = fmt.Errorf("%w: %s reference cannot be zero", common.ErrInvalid, name)
+	case resolved < 0 || resolved >= int64(count):
+		err = fmt.Errorf("%w: %s reference %d is out of range", common.ErrInvalid, name, index)
+	}
+	if err != nil && c.invalidReferenceErr == nil {
+		c.invalidReferenceErr = err
+	}
+	return resolved
+}
+
+func (c *decodeContext) handleUnknownCommand(event common.UnknownCommandEvent) error {
+	// Unknown commands do not affect the model, so there is
+	// no need to fail the decoding once the limit is reached.
+	if len(c.model.UnknownCommands) >= c.limits.MaxUnknownCommandCount {
+		return nil
+	}
+	c.model.UnknownCommands = append(c.model.UnknownCommands, UnknownCommand{
+		Name:   event.CommandName,
+		Params: event.Params,
+	})
 	return nil
 }
 
@@ -291,7 +656,7 @@ func (c *decodeContext) assureCurrentObject() {
 		return
 	}
 	c.currentObject = &Object{
-		Name: "Default",
+		Name: DefaultObjectName,
 	}
 	c.model.Objects = append(c.model.Objects, c.currentObject)
 }
@@ -301,6 +666,12 @@ func (c *decodeContext) assureCurrentMesh() {
 		return
 	}
 	c.assureCurrentObject()
+	// An object that is reentered through a group might
+	// already have a default mesh.
+	if mesh, found := c.currentObject.FindMesh(""); found {
+		c.currentMesh = mesh
+		return
+	}
 	c.currentMesh = new(Mesh)
 	c.currentObject.Meshes = append(c.currentObject.Meshes, c.currentMesh)
 }
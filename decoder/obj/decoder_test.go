@@ -1,12 +1,16 @@
 package obj_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/mokiat/go-data-front/common"
 	"github.com/mokiat/go-data-front/decoder/obj"
 )
 
@@ -14,6 +18,7 @@ var _ = Describe("Decoder", func() {
 	var (
 		testFile string
 		limits   obj.DecodeLimits
+		options  obj.DecodeOptions
 
 		model     *obj.Model
 		decodeErr error
@@ -26,6 +31,13 @@ var _ = Describe("Decoder", func() {
 		})
 	}
 
+	itShouldHaveReturnedAnInvalidError := func() {
+		GinkgoHelper()
+		It("should have returned an invalid construct error", func() {
+			Expect(decodeErr).To(MatchError(common.ErrInvalid))
+		})
+	}
+
 	itShouldNotHaveReturnedAnError := func() {
 		GinkgoHelper()
 		It("should not have returned an error", func() {
@@ -38,12 +50,13 @@ var _ = Describe("Decoder", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer file.Close()
 
-		decoder := obj.NewDecoder(limits)
+		decoder := obj.NewDecoderWithOptions(limits, options)
 		model, decodeErr = decoder.Decode(file)
 	})
 
 	BeforeEach(func() {
 		limits = obj.DefaultLimits()
+		options = obj.DefaultDecodeOptions()
 	})
 
 	When("a basic file is decoded", func() {
@@ -57,6 +70,14 @@ var _ = Describe("Decoder", func() {
 			Expect(model).ToNot(BeNil())
 		})
 
+		When("a line is longer than the limit", func() {
+			BeforeEach(func() {
+				limits.MaxLineLength = 8
+			})
+
+			itShouldHaveReturnedAnError()
+		})
+
 		It("should have decoded material libraries", func() {
 			Expect(model.MaterialLibraries).To(HaveLen(1))
 			Expect(model.MaterialLibraries[0]).To(Equal("materials.mtl"))
@@ -175,6 +196,119 @@ var _ = Describe("Decoder", func() {
 		})
 	})
 
+	When("a file with groups is decoded", func() {
+		BeforeEach(func() {
+			testFile = "valid_groups.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have decoded all groups", func() {
+			Expect(model.Groups).To(HaveLen(2))
+			Expect(model.Groups[0].Name).To(Equal("first"))
+			Expect(model.Groups[1].Name).To(Equal("second"))
+		})
+
+		It("should have assigned faces to groups", func() {
+			first := model.Groups[0]
+			second := model.Groups[1]
+
+			Expect(model.Objects).To(HaveLen(1))
+			object := model.Objects[0]
+			Expect(object.Meshes).To(HaveLen(2))
+
+			defaultMesh := object.Meshes[0]
+			Expect(defaultMesh.Faces).To(HaveLen(2))
+			Expect(defaultMesh.Faces[0].Groups).To(BeEmpty())
+			Expect(defaultMesh.Faces[1].Groups).To(Equal([]*obj.Group{first}))
+
+			redMesh := object.Meshes[1]
+			Expect(redMesh.Faces).To(HaveLen(2))
+			Expect(redMesh.Faces[0].Groups).To(Equal([]*obj.Group{first, second}))
+			Expect(redMesh.Faces[1].Groups).To(Equal([]*obj.Group{first}))
+		})
+
+		When("the group limit is not specified", func() {
+			BeforeEach(func() {
+				limits.MaxGroupCount = 0
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have decoded all groups", func() {
+				Expect(model.Groups).To(HaveLen(2))
+			})
+		})
+
+		When("the number of groups is larger than the limit", func() {
+			BeforeEach(func() {
+				limits.MaxGroupCount = 1
+			})
+
+			itShouldHaveReturnedAnError()
+		})
+
+		When("groups are treated as objects", func() {
+			BeforeEach(func() {
+				options.GroupsAsObjects = true
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have decoded an object per group", func() {
+				Expect(model.Objects).To(HaveLen(3))
+
+				object := model.Objects[0]
+				Expect(object.Name).To(Equal("Object"))
+				Expect(object.Meshes).To(HaveLen(1))
+				Expect(object.Meshes[0].Faces).To(HaveLen(1))
+
+				object = model.Objects[1]
+				Expect(object.Name).To(Equal("first"))
+				Expect(object.Meshes).To(HaveLen(1))
+				Expect(object.Meshes[0].MaterialName).To(Equal(""))
+				Expect(object.Meshes[0].Faces).To(HaveLen(2))
+
+				object = model.Objects[2]
+				Expect(object.Name).To(Equal("first second"))
+				Expect(object.Meshes).To(HaveLen(1))
+				Expect(object.Meshes[0].MaterialName).To(Equal("Red"))
+			})
+
+			It("should have assigned faces to groups as well", func() {
+				Expect(model.Groups).To(HaveLen(2))
+				face := model.Objects[2].Meshes[0].Faces[0]
+				Expect(face.Groups).To(Equal([]*obj.Group{model.Groups[0], model.Groups[1]}))
+			})
+
+			When("the number of objects is larger than the limit", func() {
+				BeforeEach(func() {
+					limits.MaxObjectCount = 2
+				})
+
+				itShouldHaveReturnedAnError()
+			})
+		})
+	})
+
+	When("a file with smoothing groups is decoded", func() {
+		BeforeEach(func() {
+			testFile = "valid_smoothing_groups.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have assigned smoothing groups to faces", func() {
+			faces := model.Objects[0].Meshes[0].Faces
+			Expect(faces).To(HaveLen(5))
+			Expect(faces[0].SmoothingGroup).To(Equal(obj.NoSmoothingGroup))
+			Expect(faces[1].SmoothingGroup).To(Equal(int64(1)))
+			Expect(faces[2].SmoothingGroup).To(Equal(int64(1)))
+			Expect(faces[3].SmoothingGroup).To(Equal(int64(2)))
+			Expect(faces[4].SmoothingGroup).To(Equal(obj.NoSmoothingGroup))
+		})
+	})
+
 	When("a file with all kinds of vertices is decoded", func() {
 		BeforeEach(func() {
 			testFile = "valid_vertices.obj"
@@ -279,6 +413,81 @@ var _ = Describe("Decoder", func() {
 		})
 	})
 
+	When("a file with unknown commands is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_unknown_commands.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have collected all unknown commands", func() {
+			Expect(model.UnknownCommands).To(Equal([]obj.UnknownCommand{
+				{Name: "cstype", Params: []string{"bspline"}},
+				{Name: "deg", Params: []string{"3"}},
+				{Name: "bevel", Params: []string{"off"}},
+				{Name: "stech", Params: []string{"cparm", "0.5"}},
+			}))
+		})
+
+		When("the number of unknown commands is larger than the limit", func() {
+			BeforeEach(func() {
+				limits.MaxUnknownCommandCount = 3
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have collected unknown commands up to the limit", func() {
+				Expect(model.UnknownCommands).To(Equal([]obj.UnknownCommand{
+					{Name: "cstype", Params: []string{"bspline"}},
+					{Name: "deg", Params: []string{"3"}},
+					{Name: "bevel", Params: []string{"off"}},
+				}))
+			})
+		})
+
+		When("the unknown command limit is not specified", func() {
+			BeforeEach(func() {
+				limits = obj.DecodeLimits{
+					MaxVertexCount: 1,
+				}
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have collected all unknown commands", func() {
+				Expect(model.UnknownCommands).To(HaveLen(4))
+			})
+		})
+	})
+
+	When("a file with material library paths that contain spaces is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_material_library_spaces.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have decoded each word as a material library", func() {
+			Expect(model.MaterialLibraries).To(Equal([]string{
+				"my", "materials.mtl", "other", "library.mtl",
+			}))
+		})
+
+		When("material libraries are treated as single paths", func() {
+			BeforeEach(func() {
+				options.SingleMaterialLibraryPath = true
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have decoded the full paths", func() {
+				Expect(model.MaterialLibraries).To(Equal([]string{
+					"my materials.mtl", "other  library.mtl",
+				}))
+			})
+		})
+	})
+
 	When("a file with multiple material references is scanned", func() {
 		BeforeEach(func() {
 			testFile = "valid_material_references.obj"
@@ -288,9 +497,10 @@ var _ = Describe("Decoder", func() {
 
 		It("should have decoded all material references", func() {
 			object := model.Objects[0]
-			Expect(object.Meshes).To(HaveLen(2))
+			Expect(object.Meshes).To(HaveLen(3))
 			Expect(object.Meshes[0].MaterialName).To(Equal("Red"))
 			Expect(object.Meshes[1].MaterialName).To(Equal("Blue"))
+			Expect(object.Meshes[2].MaterialName).To(Equal("Dark Blue"))
 		})
 
 		When("the number of material references is larger than the limit", func() {
@@ -344,6 +554,90 @@ var _ = Describe("Decoder", func() {
 		})
 	})
 
+	When("a file with vertex colors is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_vertex_colors.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have decoded the vertex colors", func() {
+			Expect(model.Vertices).To(Equal([]obj.Vertex{
+				{X: 1.0, Y: 2.0, Z: 3.0, W: 1.0, R: 0.1, G: 0.2, B: 0.3, HasColor: true},
+				{X: 4.0, Y: 5.0, Z: 6.0, W: 0.5, R: 0.4, G: 0.5, B: 0.6, HasColor: true},
+				{X: 7.0, Y: 8.0, Z: 9.0, W: 2.0},
+			}))
+		})
+	})
+
+	When("a file with lines and points is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_lines_points.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have decoded all lines", func() {
+			mesh := model.Objects[0].Meshes[0]
+			Expect(mesh.Lines).To(HaveLen(2))
+			Expect(mesh.Lines[0].References).To(HaveLen(4))
+			Expect(mesh.Lines[1].References).To(Equal([]obj.Reference{
+				{VertexIndex: 0, TexCoordIndex: 0, NormalIndex: obj.UndefinedIndex},
+				{VertexIndex: 1, TexCoordIndex: 1, NormalIndex: obj.UndefinedIndex},
+			}))
+		})
+
+		It("should have decoded all points", func() {
+			mesh := model.Objects[0].Meshes[0]
+			Expect(mesh.Points).To(Equal([]obj.Reference{
+				{VertexIndex: 0, TexCoordIndex: obj.UndefinedIndex, NormalIndex: obj.UndefinedIndex},
+				{VertexIndex: 1, TexCoordIndex: obj.UndefinedIndex, NormalIndex: obj.UndefinedIndex},
+				{VertexIndex: 2, TexCoordIndex: obj.UndefinedIndex, NormalIndex: obj.UndefinedIndex},
+				{VertexIndex: 3, TexCoordIndex: obj.UndefinedIndex, NormalIndex: obj.UndefinedIndex},
+			}))
+		})
+
+		When("the line and point limits are not specified", func() {
+			BeforeEach(func() {
+				limits.MaxLineCount = 0
+				limits.MaxLineReferenceCount = 0
+				limits.MaxPointCount = 0
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have decoded all lines and points", func() {
+				mesh := model.Objects[0].Meshes[0]
+				Expect(mesh.Lines).To(HaveLen(2))
+				Expect(mesh.Points).To(HaveLen(4))
+			})
+		})
+
+		When("the number of lines is larger than the limit", func() {
+			BeforeEach(func() {
+				limits.MaxLineCount = 1
+			})
+
+			itShouldHaveReturnedAnError()
+		})
+
+		When("the number of line references is larger than the limit", func() {
+			BeforeEach(func() {
+				limits.MaxLineReferenceCount = 3
+			})
+
+			itShouldHaveReturnedAnError()
+		})
+
+		When("the number of points is larger than the limit", func() {
+			BeforeEach(func() {
+				limits.MaxPointCount = 3
+			})
+
+			itShouldHaveReturnedAnError()
+		})
+	})
+
 	When("an object with duplicate materials is scanned", func() {
 		BeforeEach(func() {
 			limits.MaxMaterialReferenceCount = 2
@@ -437,6 +731,251 @@ var _ = Describe("Decoder", func() {
 		})
 	})
 
+	When("a file with invalid constructs is decoded", func() {
+		BeforeEach(func() {
+			testFile = "error_invalid_constructs.obj"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		When("decoding in lenient mode", func() {
+			var warnings []common.Warning
+
+			BeforeEach(func() {
+				warnings = nil
+				options.Mode = common.ScanModeLenient
+				options.WarningHandler = func(warning common.Warning) {
+					warnings = append(warnings, warning)
+				}
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have skipped the invalid constructs", func() {
+				Expect(model.Vertices).To(HaveLen(3))
+				Expect(model.Objects).To(HaveLen(1))
+				Expect(model.Objects[0].Meshes[0].Faces).To(HaveLen(1))
+			})
+
+			It("should have reported the invalid constructs", func() {
+				Expect(warnings).To(HaveLen(2))
+				Expect(warnings[0].Line).To(Equal(4))
+				Expect(warnings[0].Column).To(Equal(1))
+				Expect(warnings[0].Err).To(MatchError(common.ErrInvalid))
+				Expect(warnings[1].Line).To(Equal(6))
+				Expect(warnings[1].Column).To(Equal(7))
+				Expect(warnings[1].Command).To(Equal("v"))
+			})
+		})
+	})
+
+	When("a file with comment directives is decoded", func() {
+		BeforeEach(func() {
+			testFile = "valid_comment_directives.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have collected them as unknown commands", func() {
+			Expect(model.UnknownCommands).To(Equal([]obj.UnknownCommand{
+				{Name: "#MRGB", Params: []string{"ff112233ff445566"}},
+			}))
+		})
+
+		When("decoding in strict mode", func() {
+			BeforeEach(func() {
+				options.Mode = common.ScanModeStrict
+			})
+
+			itShouldNotHaveReturnedAnError()
+		})
+	})
+
+	When("a file with unknown commands is decoded in strict mode", func() {
+		BeforeEach(func() {
+			testFile = "valid_unknown_commands.obj"
+			options.Mode = common.ScanModeStrict
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("decoding a reference that is out of range", func() {
+		BeforeEach(func() {
+			testFile = "error_out_of_range_reference.obj"
+		})
+
+		itShouldHaveReturnedAnInvalidError()
+
+		It("should have pointed to the invalid reference", func() {
+			var parseErr *common.ParseError
+			Expect(errors.As(decodeErr, &parseErr)).To(BeTrue())
+			Expect(parseErr.Line).To(Equal(5))
+			Expect(parseErr.Column).To(Equal(7))
+			Expect(parseErr.Command).To(Equal("f"))
+		})
+
+		When("decoding in lenient mode", func() {
+			var warnings []common.Warning
+
+			BeforeEach(func() {
+				warnings = nil
+				options.Mode = common.ScanModeLenient
+				options.WarningHandler = func(warning common.Warning) {
+					warnings = append(warnings, warning)
+				}
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have skipped the faces with invalid references", func() {
+				mesh := model.Objects[0].Meshes[0]
+				Expect(mesh.Faces).To(HaveLen(1))
+			})
+
+			It("should have skipped the invalid points", func() {
+				mesh := model.Objects[0].Meshes[0]
+				Expect(mesh.Points).To(Equal([]obj.Reference{
+					{VertexIndex: 0, TexCoordIndex: obj.UndefinedIndex, NormalIndex: obj.UndefinedIndex},
+					{VertexIndex: 1, TexCoordIndex: obj.UndefinedIndex, NormalIndex: obj.UndefinedIndex},
+				}))
+			})
+
+			It("should have reported the invalid references", func() {
+				Expect(warnings).To(HaveLen(2))
+				Expect(warnings[0].Line).To(Equal(5))
+				Expect(warnings[0].Column).To(Equal(7))
+				Expect(warnings[0].Command).To(Equal("f"))
+				Expect(warnings[0].Err).To(MatchError(common.ErrInvalid))
+				Expect(warnings[1].Line).To(Equal(7))
+				Expect(warnings[1].Column).To(Equal(5))
+				Expect(warnings[1].Command).To(Equal("p"))
+				Expect(warnings[1].Err).To(MatchError(common.ErrInvalid))
+			})
+		})
+	})
+
+	When("decoding a zero reference", func() {
+		BeforeEach(func() {
+			testFile = "error_zero_reference.obj"
+		})
+
+		itShouldHaveReturnedAnInvalidError()
+	})
+
+	When("decoding a negative reference that reaches past the start", func() {
+		BeforeEach(func() {
+			testFile = "error_negative_reference.obj"
+		})
+
+		itShouldHaveReturnedAnInvalidError()
+	})
+
+	When("decoding a texture coordinate reference that is out of range", func() {
+		BeforeEach(func() {
+			testFile = "error_out_of_range_texcoord_reference.obj"
+		})
+
+		itShouldHaveReturnedAnInvalidError()
+	})
+
+	When("decoding a normal reference that is out of range", func() {
+		BeforeEach(func() {
+			testFile = "error_out_of_range_normal_reference.obj"
+		})
+
+		itShouldHaveReturnedAnInvalidError()
+	})
+
+	When("decoding with a progress handler", func() {
+		var reports []common.Progress
+
+		BeforeEach(func() {
+			reports = nil
+			testFile = "valid_basic.obj"
+			options.ProgressHandler = func(progress common.Progress) {
+				reports = append(reports, progress)
+			}
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have reported the final progress", func() {
+			Expect(reports).ToNot(BeEmpty())
+			final := reports[len(reports)-1]
+			Expect(final.BytesRead).To(Equal(int64(253)))
+			Expect(final.TotalBytes).To(Equal(int64(253)))
+			Expect(final.Elements).To(Equal(16))
+		})
+	})
+
+	When("the line length limit is not specified", func() {
+		BeforeEach(func() {
+			testFile = "valid_basic.obj"
+			limits.MaxLineLength = 0
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have applied the default line length limit", func() {
+			content := "#" + strings.Repeat(" ", common.DefaultMaxLineLength) + "\n"
+			decoder := obj.NewDecoderWithOptions(limits, options)
+			_, err := decoder.Decode(strings.NewReader(content))
+			Expect(err).To(MatchError(common.ErrLimitsExceeded))
+		})
+	})
+
+	When("decoding with a cancelled context", func() {
+		BeforeEach(func() {
+			testFile = "valid_basic.obj"
+		})
+
+		It("should have returned the context error", func() {
+			file, err := os.Open(filepath.Join("testdata", testFile))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			decoder := obj.NewDecoderWithOptions(limits, options)
+			_, err = decoder.DecodeContext(ctx, file)
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+
+	When("the context is cancelled while decoding", func() {
+		BeforeEach(func() {
+			testFile = "valid_basic.obj"
+		})
+
+		decodeWithContext := func() error {
+			file, err := os.Open(filepath.Join("testdata", testFile))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			options.ProgressInterval = -1
+			options.ProgressHandler = func(common.Progress) {
+				cancel()
+			}
+			decoder := obj.NewDecoderWithOptions(limits, options)
+			_, err = decoder.DecodeContext(ctx, file)
+			return err
+		}
+
+		It("should not check the context before the interval has passed", func() {
+			Expect(decodeWithContext()).To(Succeed())
+		})
+
+		It("should check the context at the specified interval", func() {
+			options.ContextCheckInterval = 1
+			Expect(decodeWithContext()).To(MatchError(context.Canceled))
+		})
+	})
+
 	When("decoding face without enough references", func() {
 		BeforeEach(func() {
 			testFile = "error_missing_face_data.obj"
@@ -444,6 +983,14 @@ var _ = Describe("Decoder", func() {
 
 		itShouldHaveReturnedAnError()
 	})
+
+	When("decoding line without enough references", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_line_data.obj"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
 })
 
 var _ = Describe("DecodeLimits", func() {
@@ -470,6 +1017,10 @@ var _ = Describe("DecodeLimits", func() {
 			Expect(limits.MaxObjectCount).To(Equal(1024))
 		})
 
+		It("group limit should be 1024", func() {
+			Expect(limits.MaxGroupCount).To(Equal(1024))
+		})
+
 		It("face limit should be 65536", func() {
 			Expect(limits.MaxFaceCount).To(Equal(65536))
 		})
@@ -478,6 +1029,18 @@ var _ = Describe("DecodeLimits", func() {
 			Expect(limits.MaxReferenceCount).To(Equal(16))
 		})
 
+		It("line limit should be 65536", func() {
+			Expect(limits.MaxLineCount).To(Equal(65536))
+		})
+
+		It("line reference limit should be 1024", func() {
+			Expect(limits.MaxLineReferenceCount).To(Equal(1024))
+		})
+
+		It("point limit should be 65536", func() {
+			Expect(limits.MaxPointCount).To(Equal(65536))
+		})
+
 		It("material reference limit should be 64", func() {
 			Expect(limits.MaxMaterialReferenceCount).To(Equal(64))
 		})
@@ -485,5 +1048,55 @@ var _ = Describe("DecodeLimits", func() {
 		It("material library limit should be 32", func() {
 			Expect(limits.MaxMaterialLibraryCount).To(Equal(32))
 		})
+
+		It("unknown command limit should be 1024", func() {
+			Expect(limits.MaxUnknownCommandCount).To(Equal(1024))
+		})
+
+		It("line length limit should be the default one", func() {
+			Expect(limits.MaxLineLength).To(Equal(common.DefaultMaxLineLength))
+		})
+	})
+})
+
+var _ = Describe("DecodeOptions", func() {
+	var options obj.DecodeOptions
+
+	Describe("DefaultDecodeOptions", func() {
+		BeforeEach(func() {
+			options = obj.DefaultDecodeOptions()
+		})
+
+		It("should not treat groups as objects", func() {
+			Expect(options.GroupsAsObjects).To(BeFalse())
+		})
+
+		It("should treat material libraries as a list", func() {
+			Expect(options.SingleMaterialLibraryPath).To(BeFalse())
+		})
+
+		It("should use the standard mode", func() {
+			Expect(options.Mode).To(Equal(common.ScanModeStandard))
+		})
+
+		It("should have no warning handler", func() {
+			Expect(options.WarningHandler).To(BeNil())
+		})
+
+		It("should use the default context check interval", func() {
+			Expect(options.ContextCheckInterval).To(Equal(common.DefaultContextCheckInterval))
+		})
+
+		It("should have no progress handler", func() {
+			Expect(options.ProgressHandler).To(BeNil())
+		})
+
+		It("should use the default progress interval", func() {
+			Expect(options.ProgressInterval).To(Equal(common.DefaultProgressInterval))
+		})
+
+		It("should not have a known total size", func() {
+			Expect(options.TotalBytes).To(BeZero())
+		})
 	})
 })
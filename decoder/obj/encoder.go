@@ -0,0 +1,220 @@
+package obj
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Encoder is an API that allows one to encode an object model
+// into an OBJ Wavefront resource.
+type Encoder interface {
+
+	// Encode encodes the specified Model into an OBJ Wavefront
+	// resource, which is written to the io.Writer.
+	//
+	// The UnknownCommands of the Model are written after the
+	// material library declarations and before all vertex data,
+	// regardless of where they were originally declared.
+	//
+	// If encoding fails for some reason, an error is returned.
+	Encode(io.Writer, *Model) error
+}
+
+// NewEncoder creates a new Encoder instance.
+func NewEncoder() Encoder {
+	return &encoder{}
+}
+
+type encoder struct {
+}
+
+func (e *encoder) Encode(writer io.Writer, model *Model) error {
+	encodeCtx := newEncodeContext(writer)
+	encodeCtx.writeModel(model)
+	return encodeCtx.Flush()
+}
+
+func newEncodeContext(writer io.Writer) *encodeContext {
+	return &encodeContext{
+		writer: bufio.NewWriter(writer),
+	}
+}
+
+type encodeContext struct {
+	writer           *bufio.Writer
+	currentGroups    []*Group
+	currentSmoothing int64
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+// and returns the first error that occurred during writing.
+func (c *encodeContext) Flush() error {
+	return c.writer.Flush()
+}
+
+func (c *encodeContext) writeModel(model *Model) {
+	for _, library := range model.MaterialLibraries {
+		c.writeCommand("mtllib", library)
+	}
+	for _, command := range model.UnknownCommands {
+		c.writeCommand(command.Name, strings.Join(command.Params, " "))
+	}
+	for _, vertex := range model.Vertices {
+		c.writeVertex(vertex)
+	}
+	for _, texCoord := range model.TexCoords {
+		c.writeTexCoord(texCoord)
+	}
+	for _, normal := range model.Normals {
+		c.writeNormal(normal)
+	}
+	for _, object := range model.Objects {
+		c.writeObject(object)
+	}
+}
+
+func (c *encodeContext) writeVertex(vertex Vertex) {
+	c.writer.WriteString("v ")
+	c.writeFloats(vertex.X, vertex.Y, vertex.Z)
+	// A color can only be combined with W in the seven
+	// component form, so W is omitted only when it is
+	// the default one.
+	if vertex.W != 1.0 {
+		c.writer.WriteByte(' ')
+		c.writeFloats(vertex.W)
+	}
+	if vertex.HasColor {
+		c.writer.WriteByte(' ')
+		c.writeFloats(vertex.R, vertex.G, vertex.B)
+	}
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeTexCoord(texCoord TexCoord) {
+	c.writer.WriteString("vt ")
+	c.writeFloats(texCoord.U, texCoord.V)
+	if texCoord.W != 0.0 {
+		c.writer.WriteByte(' ')
+		c.writeFloats(texCoord.W)
+	}
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeNormal(normal Normal) {
+	c.writer.WriteString("vn ")
+	c.writeFloats(normal.X, normal.Y, normal.Z)
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeObject(object *Object) {
+	// An object declaration without a name is not valid, so the
+	// name that the decoder uses for implicit objects is used instead.
+	name := object.Name
+	if name == "" {
+		name = DefaultObjectName
+	}
+	c.writeCommand("o", name)
+	for i, mesh := range object.Meshes {
+		// The decoder creates an unnamed mesh when faces are
+		// declared before any material reference, so there is
+		// no need to be explicit about it.
+		if i > 0 || mesh.MaterialName != "" {
+			c.writeCommand("usemtl", mesh.MaterialName)
+		}
+		for _, face := range mesh.Faces {
+			c.writeGroups(face.Groups)
+			c.writeSmoothingGroup(face.SmoothingGroup)
+			c.writeFace(face)
+		}
+		for _, line := range mesh.Lines {
+			c.writeElement("l", line.References)
+		}
+		for _, point := range mesh.Points {
+			c.writeElement("p", []Reference{point})
+		}
+	}
+}
+
+func (c *encodeContext) writeGroups(groups []*Group) {
+	if slices.Equal(groups, c.currentGroups) {
+		return
+	}
+	c.currentGroups = groups
+	// A declaration without names places the faces that follow
+	// in the `default` group, which is the closest approximation
+	// of faces that have no group.
+	c.writer.WriteString("g")
+	for _, group := range groups {
+		c.writer.WriteByte(' ')
+		c.writer.WriteString(group.Name)
+	}
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeSmoothingGroup(group int64) {
+	if group == c.currentSmoothing {
+		return
+	}
+	c.currentSmoothing = group
+	if group == NoSmoothingGroup {
+		c.writeCommand("s", "off")
+	} else {
+		c.writeCommand("s", strconv.FormatInt(group, 10))
+	}
+}
+
+func (c *encodeContext) writeFace(face *Face) {
+	c.writeElement("f", face.References)
+}
+
+func (c *encodeContext) writeElement(name string, references []Reference) {
+	c.writer.WriteString(name)
+	for _, reference := range references {
+		c.writer.WriteByte(' ')
+		c.writeReference(reference)
+	}
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeReference(reference Reference) {
+	c.writeIndex(reference.VertexIndex)
+	switch {
+	case reference.HasTexCoord() && reference.HasNormal():
+		c.writer.WriteByte('/')
+		c.writeIndex(reference.TexCoordIndex)
+		c.writer.WriteByte('/')
+		c.writeIndex(reference.NormalIndex)
+	case reference.HasTexCoord():
+		c.writer.WriteByte('/')
+		c.writeIndex(reference.TexCoordIndex)
+	case reference.HasNormal():
+		c.writer.WriteString("//")
+		c.writeIndex(reference.NormalIndex)
+	}
+}
+
+func (c *encodeContext) writeIndex(index int64) {
+	// OBJ resources use 1-based indexing.
+	c.writer.WriteString(strconv.FormatInt(index+1, 10))
+}
+
+func (c *encodeContext) writeCommand(name, param string) {
+	c.writer.WriteString(name)
+	if param != "" {
+		c.writer.WriteByte(' ')
+		c.writer.WriteString(param)
+	}
+	c.writer.WriteByte('\n')
+}
+
+func (c *encodeContext) writeFloats(values ...float64) {
+	for i, value := range values {
+		if i > 0 {
+			c.writer.WriteByte(' ')
+		}
+		c.writer.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	}
+}
@@ -0,0 +1,256 @@
+package obj_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+	"github.com/mokiat/go-data-front/internal/testutil"
+)
+
+var _ = Describe("Encoder", func() {
+	var (
+		model     *obj.Model
+		buffer    *bytes.Buffer
+		encodeErr error
+	)
+
+	JustBeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder := obj.NewEncoder()
+		encodeErr = encoder.Encode(buffer, model)
+	})
+
+	When("a model is encoded", func() {
+		BeforeEach(func() {
+			model = &obj.Model{
+				MaterialLibraries: []string{"first.mtl", "second.mtl"},
+				Vertices: []obj.Vertex{
+					{X: 1.0, Y: 2.0, Z: 3.0, W: 1.0},
+					{X: -0.5, Y: 0.25, Z: 0.0, W: 1.0},
+					{X: 4.0, Y: 5.0, Z: 6.0, W: 0.5},
+				},
+				TexCoords: []obj.TexCoord{
+					{U: 0.1, V: 0.2, W: 0.0},
+					{U: 0.3, V: 0.4, W: 0.5},
+				},
+				Normals: []obj.Normal{
+					{X: 0.0, Y: 1.0, Z: 0.0},
+				},
+				Objects: []*obj.Object{
+					{
+						Name: "First",
+						Meshes: []*obj.Mesh{
+							{
+								MaterialName: "",
+								Faces: []*obj.Face{
+									{References: []obj.Reference{
+										{VertexIndex: 0, TexCoordIndex: obj.UndefinedIndex, NormalIndex: obj.UndefinedIndex},
+										{VertexIndex: 1, TexCoordIndex: 0, NormalIndex: obj.UndefinedIndex},
+										{VertexIndex: 2, TexCoordIndex: obj.UndefinedIndex, NormalIndex: 0},
+									}},
+								},
+							},
+							{
+								MaterialName: "Red",
+								Faces: []*obj.Face{
+									{References: []obj.Reference{
+										{VertexIndex: 2, TexCoordIndex: 1, NormalIndex: 0},
+										{VertexIndex: 1, TexCoordIndex: 0, NormalIndex: 0},
+										{VertexIndex: 0, TexCoordIndex: 1, NormalIndex: 0},
+									}},
+								},
+							},
+						},
+					},
+					{
+						Name: "Second",
+					},
+				},
+			}
+		})
+
+		It("should not have returned an error", func() {
+			Expect(encodeErr).ToNot(HaveOccurred())
+		})
+
+		It("should have written the resource", func() {
+			Expect(buffer.String()).To(Equal(
+				"mtllib first.mtl\n" +
+					"mtllib second.mtl\n" +
+					"v 1 2 3\n" +
+					"v -0.5 0.25 0\n" +
+					"v 4 5 6 0.5\n" +
+					"vt 0.1 0.2\n" +
+					"vt 0.3 0.4 0.5\n" +
+					"vn 0 1 0\n" +
+					"o First\n" +
+					"f 1 2/1 3//1\n" +
+					"usemtl Red\n" +
+					"f 3/2/1 2/1/1 1/2/1\n" +
+					"o Second\n",
+			))
+		})
+
+		It("should be possible to decode the resource", func() {
+			decoder := obj.NewDecoder(obj.DefaultLimits())
+			decodedModel, err := decoder.Decode(buffer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decodedModel).To(Equal(model))
+		})
+	})
+
+	When("a model with an unnamed object is encoded", func() {
+		BeforeEach(func() {
+			model = &obj.Model{
+				Vertices: []obj.Vertex{
+					{X: 1.0, Y: 2.0, Z: 3.0, W: 1.0},
+				},
+				Objects: []*obj.Object{
+					{
+						Name: "",
+						Meshes: []*obj.Mesh{
+							{
+								Points: []obj.Reference{
+									{VertexIndex: 0, TexCoordIndex: obj.UndefinedIndex, NormalIndex: obj.UndefinedIndex},
+								},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("should not have returned an error", func() {
+			Expect(encodeErr).ToNot(HaveOccurred())
+		})
+
+		It("should have written the default object name", func() {
+			Expect(buffer.String()).To(Equal(
+				"v 1 2 3\n" +
+					"o Default\n" +
+					"p 1\n",
+			))
+		})
+
+		It("should be possible to decode the resource", func() {
+			decoder := obj.NewDecoder(obj.DefaultLimits())
+			decodedModel, err := decoder.Decode(buffer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decodedModel.Objects).To(HaveLen(1))
+			Expect(decodedModel.Objects[0].Name).To(Equal(obj.DefaultObjectName))
+			Expect(decodedModel.Objects[0].Meshes).To(Equal(model.Objects[0].Meshes))
+		})
+	})
+
+	When("a decoded file is encoded", func() {
+		var originalModel *obj.Model
+
+		decodeTestFile := func(testFile string) *obj.Model {
+			GinkgoHelper()
+			file, err := os.Open(filepath.Join("testdata", testFile))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			decoder := obj.NewDecoder(obj.DefaultLimits())
+			decodedModel, err := decoder.Decode(file)
+			Expect(err).ToNot(HaveOccurred())
+			return decodedModel
+		}
+
+		itShouldProduceAnEqualModel := func() {
+			GinkgoHelper()
+			It("should produce an equal model when decoded again", func() {
+				Expect(encodeErr).ToNot(HaveOccurred())
+
+				decoder := obj.NewDecoder(obj.DefaultLimits())
+				decodedModel, err := decoder.Decode(buffer)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decodedModel).To(Equal(originalModel))
+			})
+		}
+
+		Context("basic file", func() {
+			BeforeEach(func() {
+				originalModel = decodeTestFile("valid_basic.obj")
+				model = originalModel
+			})
+
+			itShouldProduceAnEqualModel()
+		})
+
+		Context("file with smoothing groups", func() {
+			BeforeEach(func() {
+				originalModel = decodeTestFile("valid_smoothing_groups.obj")
+				model = originalModel
+			})
+
+			itShouldProduceAnEqualModel()
+		})
+
+		Context("file with groups", func() {
+			BeforeEach(func() {
+				originalModel = decodeTestFile("valid_groups.obj")
+				model = originalModel
+			})
+
+			itShouldProduceAnEqualModel()
+		})
+
+		Context("file with vertex colors", func() {
+			BeforeEach(func() {
+				originalModel = decodeTestFile("valid_vertex_colors.obj")
+				model = originalModel
+			})
+
+			itShouldProduceAnEqualModel()
+		})
+
+		Context("file with material references", func() {
+			BeforeEach(func() {
+				originalModel = decodeTestFile("valid_material_references.obj")
+				model = originalModel
+			})
+
+			itShouldProduceAnEqualModel()
+		})
+
+		Context("file with unknown commands", func() {
+			BeforeEach(func() {
+				originalModel = decodeTestFile("valid_unknown_commands.obj")
+				model = originalModel
+			})
+
+			itShouldProduceAnEqualModel()
+		})
+
+		Context("file with lines and points", func() {
+			BeforeEach(func() {
+				originalModel = decodeTestFile("valid_lines_points.obj")
+				model = originalModel
+			})
+
+			itShouldProduceAnEqualModel()
+		})
+	})
+
+	When("the writer fails", func() {
+		var errStubbed = errors.New("stubbed to fail")
+
+		BeforeEach(func() {
+			model = &obj.Model{
+				MaterialLibraries: []string{"materials.mtl"},
+			}
+		})
+
+		It("should have returned the writer error", func() {
+			err := obj.NewEncoder().Encode(testutil.NewFailingWriter(errStubbed), model)
+			Expect(err).To(Equal(errStubbed))
+		})
+	})
+})
@@ -0,0 +1,299 @@
+package geom
+
+import (
+	"github.com/mokiat/go-data-front/decoder/obj"
+)
+
+// Analysis holds statistics and bounding volumes of a Model, an
+// Object or a Mesh.
+//
+// Only vertices that are referenced by faces, lines or points are
+// taken into account.
+type Analysis struct {
+
+	// Bounds holds the axis-aligned bounding box of the vertices.
+	Bounds Box
+
+	// Sphere holds a sphere that encloses all the vertices. It is
+	// not guaranteed to be the smallest such sphere.
+	Sphere Sphere
+
+	// Centroid holds the average position of the vertices.
+	Centroid Point
+
+	// VertexCount holds the number of unique vertices. If it is
+	// zero, then Bounds, Sphere and Centroid should be ignored.
+	VertexCount int
+
+	// SurfaceArea holds the total area of all the faces.
+	SurfaceArea float64
+
+	// Counts holds the total element counts.
+	Counts Counts
+
+	// NGonHistogram maps a reference count to the number of faces
+	// that have that many references.
+	NGonHistogram map[int]int
+
+	// Attributes specifies which types of information are present.
+	Attributes Attributes
+
+	// Objects holds the element counts of each analyzed object.
+	// It is empty when a single Mesh is analyzed.
+	Objects []ObjectCounts
+}
+
+// Point represents a position in space.
+type Point struct {
+
+	// X coordinate of this point.
+	X float64
+
+	// Y coordinate of this point.
+	Y float64
+
+	// Z coordinate of this point.
+	Z float64
+}
+
+// Box represents an axis-aligned bounding box.
+type Box struct {
+
+	// Min holds the corner of the box with the lowest coordinates.
+	Min Point
+
+	// Max holds the corner of the box with the highest coordinates.
+	Max Point
+}
+
+// Sphere represents a bounding sphere.
+type Sphere struct {
+
+	// Center holds the center of the sphere.
+	Center Point
+
+	// Radius holds the radius of the sphere.
+	Radius float64
+}
+
+// Counts holds the number of elements in a section of a model.
+type Counts struct {
+
+	// Faces holds the number of faces.
+	Faces int
+
+	// Triangles holds the number of triangles that the faces are
+	// made of once triangulated.
+	Triangles int
+
+	// Lines holds the number of lines.
+	Lines int
+
+	// Points holds the number of points.
+	Points int
+
+	// References holds the number of references that are used by
+	// faces, lines and points.
+	References int
+}
+
+func (c *Counts) add(other Counts) {
+	c.Faces += other.Faces
+	c.Triangles += other.Triangles
+	c.Lines += other.Lines
+	c.Points += other.Points
+	c.References += other.References
+}
+
+// ObjectCounts holds the element counts of a single Object.
+type ObjectCounts struct {
+
+	// Name holds the name of the object.
+	Name string
+
+	// Counts holds the total element counts of the object.
+	Counts Counts
+
+	// Meshes holds the element counts of each mesh of the object.
+	Meshes []MeshCounts
+}
+
+// MeshCounts holds the element counts of a single Mesh.
+type MeshCounts struct {
+
+	// MaterialName holds the material name of the mesh.
+	MaterialName string
+
+	// Counts holds the element counts of the mesh.
+	Counts Counts
+}
+
+// Attributes specifies which types of information are present in
+// the analyzed elements.
+type Attributes struct {
+
+	// TexCoords specifies whether at least one reference has
+	// texture coordinate information.
+	TexCoords bool
+
+	// Normals specifies whether at least one reference has normal
+	// information.
+	Normals bool
+
+	// Colors specifies whether at least one referenced vertex has
+	// color information.
+	Colors bool
+}
+
+// AnalyzeModel calculates the Analysis of all the objects in the
+// specified Model in a single pass.
+func AnalyzeModel(model *obj.Model) *Analysis {
+	analyzer := newAnalyzer(model)
+	for _, object := range model.Objects {
+		analyzer.addObject(object)
+	}
+	return analyzer.finish()
+}
+
+// AnalyzeObject calculates the Analysis of the specified Object in
+// a single pass.
+func AnalyzeObject(model *obj.Model, object *obj.Object) *Analysis {
+	analyzer := newAnalyzer(model)
+	analyzer.addObject(object)
+	return analyzer.finish()
+}
+
+// AnalyzeMesh calculates the Analysis of the specified Mesh in a
+// single pass.
+func AnalyzeMesh(model *obj.Model, mesh *obj.Mesh) *Analysis {
+	analyzer := newAnalyzer(model)
+	analyzer.analysis.Counts = analyzer.addMesh(mesh)
+	return analyzer.finish()
+}
+
+func newAnalyzer(model *obj.Model) *analyzer {
+	return &analyzer{
+		model: model,
+		analysis: &Analysis{
+			NGonHistogram: make(map[int]int),
+		},
+		visited: make(map[int64]struct{}),
+	}
+}
+
+type analyzer struct {
+	model        *obj.Model
+	analysis     *Analysis
+	visited      map[int64]struct{}
+	min          vec3
+	max          vec3
+	sum          vec3
+	sphereCenter vec3
+	sphereRadius float64
+}
+
+func (a *analyzer) addObject(object *obj.Object) {
+	objectCounts := ObjectCounts{
+		Name: object.Name,
+	}
+	for _, mesh := range object.Meshes {
+		meshCounts := a.addMesh(mesh)
+		objectCounts.Meshes = append(objectCounts.Meshes, MeshCounts{
+			MaterialName: mesh.MaterialName,
+			Counts:       meshCounts,
+		})
+		objectCounts.Counts.add(meshCounts)
+	}
+	a.analysis.Objects = append(a.analysis.Objects, objectCounts)
+	a.analysis.Counts.add(objectCounts.Counts)
+}
+
+func (a *analyzer) addMesh(mesh *obj.Mesh) Counts {
+	var counts Counts
+	for _, face := range mesh.Faces {
+		referenceCount := len(face.References)
+		counts.Faces++
+		counts.Triangles += max(0, referenceCount-2)
+		counts.References += referenceCount
+		a.analysis.NGonHistogram[referenceCount]++
+
+		positions := make([]vec3, referenceCount)
+		for i, ref := range face.References {
+			positions[i] = a.addReference(ref)
+		}
+		a.analysis.SurfaceArea += polygonNormal(positions).length() / 2.0
+	}
+	for _, line := range mesh.Lines {
+		counts.Lines++
+		counts.References += len(line.References)
+		for _, ref := range line.References {
+			a.addReference(ref)
+		}
+	}
+	for _, ref := range mesh.Points {
+		counts.Points++
+		counts.References++
+		a.addReference(ref)
+	}
+	return counts
+}
+
+// addReference records the information of the specified Reference
+// and returns the position of its vertex.
+func (a *analyzer) addReference(ref obj.Reference) vec3 {
+	if ref.HasTexCoord() {
+		a.analysis.Attributes.TexCoords = true
+	}
+	if ref.HasNormal() {
+		a.analysis.Attributes.Normals = true
+	}
+
+	vertex := a.model.GetVertexFromReference(ref)
+	point := vec3{x: vertex.X, y: vertex.Y, z: vertex.Z}
+	if _, ok := a.visited[ref.VertexIndex]; ok {
+		return point
+	}
+	a.visited[ref.VertexIndex] = struct{}{}
+
+	if vertex.HasColor {
+		a.analysis.Attributes.Colors = true
+	}
+	if a.analysis.VertexCount == 0 {
+		a.min = point
+		a.max = point
+		a.sphereCenter = point
+	}
+	a.analysis.VertexCount++
+	a.min = vec3{x: min(a.min.x, point.x), y: min(a.min.y, point.y), z: min(a.min.z, point.z)}
+	a.max = vec3{x: max(a.max.x, point.x), y: max(a.max.y, point.y), z: max(a.max.z, point.z)}
+	a.sum = a.sum.add(point)
+
+	// The sphere is grown just enough to enclose the new point,
+	// which makes a single pass possible.
+	offset := point.sub(a.sphereCenter)
+	if distance := offset.length(); distance > a.sphereRadius {
+		radius := (a.sphereRadius + distance) / 2.0
+		a.sphereCenter = a.sphereCenter.add(offset.scale((radius - a.sphereRadius) / distance))
+		a.sphereRadius = radius
+	}
+	return point
+}
+
+func (a *analyzer) finish() *Analysis {
+	if a.analysis.VertexCount > 0 {
+		a.analysis.Bounds = Box{
+			Min: toPoint(a.min),
+			Max: toPoint(a.max),
+		}
+		a.analysis.Sphere = Sphere{
+			Center: toPoint(a.sphereCenter),
+			Radius: a.sphereRadius,
+		}
+		a.analysis.Centroid = toPoint(a.sum.scale(1.0 / float64(a.analysis.VertexCount)))
+	}
+	return a.analysis
+}
+
+func toPoint(v vec3) Point {
+	return Point{X: v.x, Y: v.y, Z: v.z}
+}
@@ -0,0 +1,239 @@
+package geom_test
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+	"github.com/mokiat/go-data-front/decoder/obj/geom"
+)
+
+var _ = Describe("Analysis", func() {
+	var (
+		model        *obj.Model
+		firstObject  *obj.Object
+		secondObject *obj.Object
+		lineMesh     *obj.Mesh
+		analysis     *geom.Analysis
+	)
+
+	itShouldEncloseAllVertices := func() {
+		GinkgoHelper()
+
+		It("should have a sphere that encloses the vertices", func() {
+			center := analysis.Sphere.Center
+			for _, index := range []int{0, 1, 2, 3, 4, 5, 6} {
+				vertex := model.Vertices[index]
+				distance := math.Sqrt(
+					(vertex.X-center.X)*(vertex.X-center.X) +
+						(vertex.Y-center.Y)*(vertex.Y-center.Y) +
+						(vertex.Z-center.Z)*(vertex.Z-center.Z),
+				)
+				Expect(distance).To(BeNumerically("<=", analysis.Sphere.Radius+0.0001))
+			}
+		})
+	}
+
+	BeforeEach(func() {
+		model = &obj.Model{
+			Vertices: testVertices(
+				0, 0, 0,
+				2, 0, 0,
+				2, 2, 0,
+				0, 2, 0,
+				0, 0, 2,
+				4, 4, 4,
+				-2, 0, 0,
+				9, 9, 9, // not referenced
+			),
+			TexCoords: []obj.TexCoord{
+				{U: 0.5, V: 0.5},
+			},
+		}
+		model.Vertices[5].HasColor = true
+
+		quad := testFace(0, 1, 2, 3)
+		quad.References[0].TexCoordIndex = 0
+		lineMesh = &obj.Mesh{
+			MaterialName: "Red",
+			Lines: []*obj.Line{
+				{References: testFace(0, 5).References},
+			},
+		}
+		firstObject = &obj.Object{
+			Name: "First",
+			Meshes: []*obj.Mesh{
+				{Faces: []*obj.Face{quad, testFace(0, 1, 4)}},
+				lineMesh,
+			},
+		}
+		secondObject = &obj.Object{
+			Name: "Second",
+			Meshes: []*obj.Mesh{
+				{
+					MaterialName: "Blue",
+					Points:       testFace(6, 3).References,
+				},
+			},
+		}
+		model.Objects = []*obj.Object{firstObject, secondObject}
+	})
+
+	When("a model is analyzed", func() {
+		JustBeforeEach(func() {
+			analysis = geom.AnalyzeModel(model)
+		})
+
+		It("should have counted the referenced vertices", func() {
+			Expect(analysis.VertexCount).To(Equal(7))
+		})
+
+		It("should have calculated the bounds", func() {
+			Expect(analysis.Bounds).To(Equal(geom.Box{
+				Min: geom.Point{X: -2.0, Y: 0.0, Z: 0.0},
+				Max: geom.Point{X: 4.0, Y: 4.0, Z: 4.0},
+			}))
+		})
+
+		itShouldEncloseAllVertices()
+
+		It("should have calculated the centroid", func() {
+			Expect(analysis.Centroid.X).To(BeNumerically("~", 6.0/7.0))
+			Expect(analysis.Centroid.Y).To(BeNumerically("~", 8.0/7.0))
+			Expect(analysis.Centroid.Z).To(BeNumerically("~", 6.0/7.0))
+		})
+
+		It("should have calculated the surface area", func() {
+			Expect(analysis.SurfaceArea).To(BeNumerically("~", 6.0))
+		})
+
+		It("should have calculated the total counts", func() {
+			Expect(analysis.Counts).To(Equal(geom.Counts{
+				Faces:      2,
+				Triangles:  3,
+				Lines:      1,
+				Points:     2,
+				References: 11,
+			}))
+		})
+
+		It("should have calculated the counts per object and mesh", func() {
+			Expect(analysis.Objects).To(Equal([]geom.ObjectCounts{
+				{
+					Name: "First",
+					Counts: geom.Counts{
+						Faces:      2,
+						Triangles:  3,
+						Lines:      1,
+						References: 9,
+					},
+					Meshes: []geom.MeshCounts{
+						{
+							MaterialName: "",
+							Counts: geom.Counts{
+								Faces:      2,
+								Triangles:  3,
+								References: 7,
+							},
+						},
+						{
+							MaterialName: "Red",
+							Counts: geom.Counts{
+								Lines:      1,
+								References: 2,
+							},
+						},
+					},
+				},
+				{
+					Name: "Second",
+					Counts: geom.Counts{
+						Points:     2,
+						References: 2,
+					},
+					Meshes: []geom.MeshCounts{
+						{
+							MaterialName: "Blue",
+							Counts: geom.Counts{
+								Points:     2,
+								References: 2,
+							},
+						},
+					},
+				},
+			}))
+		})
+
+		It("should have calculated the n-gon histogram", func() {
+			Expect(analysis.NGonHistogram).To(Equal(map[int]int{
+				3: 1,
+				4: 1,
+			}))
+		})
+
+		It("should have determined the present attributes", func() {
+			Expect(analysis.Attributes).To(Equal(geom.Attributes{
+				TexCoords: true,
+				Normals:   false,
+				Colors:    true,
+			}))
+		})
+	})
+
+	When("an object is analyzed", func() {
+		JustBeforeEach(func() {
+			analysis = geom.AnalyzeObject(model, secondObject)
+		})
+
+		It("should have only taken the object into account", func() {
+			Expect(analysis.VertexCount).To(Equal(2))
+			Expect(analysis.Bounds).To(Equal(geom.Box{
+				Min: geom.Point{X: -2.0, Y: 0.0, Z: 0.0},
+				Max: geom.Point{X: 0.0, Y: 2.0, Z: 0.0},
+			}))
+			Expect(analysis.Counts).To(Equal(geom.Counts{
+				Points:     2,
+				References: 2,
+			}))
+			Expect(analysis.Objects).To(HaveLen(1))
+			Expect(analysis.Attributes).To(Equal(geom.Attributes{}))
+		})
+	})
+
+	When("a mesh is analyzed", func() {
+		JustBeforeEach(func() {
+			analysis = geom.AnalyzeMesh(model, lineMesh)
+		})
+
+		It("should have only taken the mesh into account", func() {
+			Expect(analysis.VertexCount).To(Equal(2))
+			Expect(analysis.Sphere.Center).To(Equal(geom.Point{X: 2.0, Y: 2.0, Z: 2.0}))
+			Expect(analysis.Sphere.Radius).To(BeNumerically("~", math.Sqrt(12.0)))
+			Expect(analysis.SurfaceArea).To(BeZero())
+			Expect(analysis.Counts).To(Equal(geom.Counts{
+				Lines:      1,
+				References: 2,
+			}))
+			Expect(analysis.NGonHistogram).To(BeEmpty())
+			Expect(analysis.Objects).To(BeEmpty())
+			Expect(analysis.Attributes).To(Equal(geom.Attributes{
+				Colors: true,
+			}))
+		})
+	})
+
+	When("an empty model is analyzed", func() {
+		JustBeforeEach(func() {
+			analysis = geom.AnalyzeModel(&obj.Model{})
+		})
+
+		It("should have no vertices", func() {
+			Expect(analysis.VertexCount).To(BeZero())
+			Expect(analysis.Bounds).To(Equal(geom.Box{}))
+			Expect(analysis.Sphere).To(Equal(geom.Sphere{}))
+			Expect(analysis.Counts).To(Equal(geom.Counts{}))
+		})
+	})
+})
@@ -0,0 +1,354 @@
+package geom
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+)
+
+// Attribute represents a type of data that can be stored for each
+// vertex within a VertexBuffer.
+type Attribute int
+
+const (
+	// AttributePosition represents the X, Y and Z coordinates
+	// of the Vertex.
+	AttributePosition Attribute = iota
+
+	// AttributeTexCoord represents the U and V coordinates of
+	// the TexCoord. Zeroes are used for references that do not
+	// have texture information.
+	AttributeTexCoord
+
+	// AttributeNormal represents the X, Y and Z coordinates of
+	// the Normal. Zeroes are used for references that do not
+	// have directional information.
+	AttributeNormal
+
+	// AttributeColor represents the R, G and B components of
+	// the Vertex color. White is used for vertices that do not
+	// have color information.
+	AttributeColor
+)
+
+// ComponentCount returns the number of float32 values that are
+// used to store this Attribute. Unknown attributes are not stored,
+// so zero is returned for them.
+func (a Attribute) ComponentCount() int {
+	switch a {
+	case AttributePosition, AttributeNormal, AttributeColor:
+		return 3
+	case AttributeTexCoord:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Layout specifies how the attributes of the vertices are arranged
+// within the vertex buffers.
+type Layout int
+
+const (
+	// LayoutInterleaved specifies that all attributes of a vertex
+	// are stored next to each other in a single VertexBuffer.
+	LayoutInterleaved Layout = iota
+
+	// LayoutPlanar specifies that each attribute is stored in a
+	// separate VertexBuffer.
+	LayoutPlanar
+)
+
+// IndexFormat specifies the type of the values in an index buffer.
+type IndexFormat int
+
+const (
+	// IndexFormatUint16 specifies that indices are stored as
+	// uint16 values.
+	IndexFormatUint16 IndexFormat = iota
+
+	// IndexFormatUint32 specifies that indices are stored as
+	// uint32 values.
+	IndexFormatUint32
+)
+
+// BufferOptions specifies how the faces of a mesh should be
+// converted into buffers.
+type BufferOptions struct {
+
+	// Attributes specifies which attributes should be stored for
+	// each vertex and in what order.
+	Attributes []Attribute
+
+	// Layout specifies how the attributes should be arranged.
+	Layout Layout
+}
+
+// DefaultBufferOptions returns some default BufferOptions.
+// Users can take the result and modify specific parameters.
+func DefaultBufferOptions() BufferOptions {
+	return BufferOptions{
+		Attributes: []Attribute{
+			AttributePosition,
+			AttributeTexCoord,
+			AttributeNormal,
+		},
+		Layout: LayoutInterleaved,
+	}
+}
+
+// Buffers holds the vertex and index data of flattened faces in a
+// form that can be uploaded to a GPU.
+type Buffers struct {
+
+	// VertexCount holds the number of unique vertices.
+	VertexCount int
+
+	// VertexBuffers holds the vertex data. When LayoutInterleaved
+	// is used, there is a single buffer that holds all attributes.
+	// When LayoutPlanar is used, there is a buffer per attribute,
+	// in the order in which the attributes were requested.
+	VertexBuffers []VertexBuffer
+
+	// IndexFormat specifies which of the index buffers holds the
+	// indices. The smallest format that can fit all the indices
+	// is chosen, where the 16 bit index 0xFFFF is never used, since
+	// it is reserved for primitive restart.
+	IndexFormat IndexFormat
+
+	// Indices16 holds the indices of the triangles when the
+	// IndexFormat is IndexFormatUint16.
+	Indices16 []uint16
+
+	// Indices32 holds the indices of the triangles when the
+	// IndexFormat is IndexFormatUint32.
+	Indices32 []uint32
+
+	// Ranges holds the sections of the index buffer that belong to
+	// the individual meshes.
+	Ranges []IndexRange
+}
+
+// IndexCount returns the number of indices in the index buffer.
+func (b *Buffers) IndexCount() int {
+	if b.IndexFormat == IndexFormatUint16 {
+		return len(b.Indices16)
+	}
+	return len(b.Indices32)
+}
+
+// VertexBuffer holds the data of one or more vertex attributes.
+type VertexBuffer struct {
+
+	// Attributes holds the attributes that are stored in this
+	// buffer, in the order in which they appear for each vertex.
+	Attributes []Attribute
+
+	// Stride holds the number of float32 values that are used to
+	// store a single vertex.
+	Stride int
+
+	// Data holds the vertex data.
+	Data []float32
+}
+
+// Offset returns the position of the first float32 value of the
+// specified Attribute within a single vertex. If the buffer does
+// not hold the Attribute, then false is returned.
+func (b *VertexBuffer) Offset(attribute Attribute) (int, bool) {
+	offset := 0
+	for _, candidate := range b.Attributes {
+		if candidate == attribute {
+			return offset, true
+		}
+		offset += candidate.ComponentCount()
+	}
+	return 0, false
+}
+
+// IndexRange represents a section of an index buffer that should be
+// rendered with a specific material.
+type IndexRange struct {
+
+	// MaterialName holds the name of the material of the mesh
+	// from which the section originates.
+	MaterialName string
+
+	// IndexOffset holds the position of the first index of the
+	// section.
+	IndexOffset int
+
+	// IndexCount holds the number of indices in the section.
+	IndexCount int
+}
+
+// FlattenMesh converts the faces of the specified Mesh into buffers.
+// Faces are triangulated via TriangulateMesh, while lines and points
+// are ignored.
+//
+// References that point to the same data for the requested
+// attributes are merged into a single vertex.
+func FlattenMesh(model *obj.Model, mesh *obj.Mesh, options BufferOptions) *Buffers {
+	builder := newBufferBuilder(model, options)
+	builder.addMesh(mesh)
+	return builder.build()
+}
+
+// FlattenObject converts the faces of all the meshes of the
+// specified Object into buffers that share the vertex data. Each
+// mesh is represented by an IndexRange.
+//
+// See FlattenMesh for more information.
+func FlattenObject(model *obj.Model, object *obj.Object, options BufferOptions) *Buffers {
+	builder := newBufferBuilder(model, options)
+	for _, mesh := range object.Meshes {
+		builder.addMesh(mesh)
+	}
+	return builder.build()
+}
+
+func newBufferBuilder(model *obj.Model, options BufferOptions) *bufferBuilder {
+	var vertexBuffers []VertexBuffer
+	switch options.Layout {
+	case LayoutPlanar:
+		vertexBuffers = make([]VertexBuffer, len(options.Attributes))
+		for i, attribute := range options.Attributes {
+			vertexBuffers[i] = VertexBuffer{
+				Attributes: []Attribute{attribute},
+				Stride:     attribute.ComponentCount(),
+			}
+		}
+	default:
+		stride := 0
+		for _, attribute := range options.Attributes {
+			stride += attribute.ComponentCount()
+		}
+		vertexBuffers = []VertexBuffer{
+			{
+				// The attributes are copied, so that later changes to
+				// the options do not affect the built buffers.
+				Attributes: slices.Clone(options.Attributes),
+				Stride:     stride,
+			},
+		}
+	}
+
+	return &bufferBuilder{
+		model:         model,
+		options:       options,
+		vertexBuffers: vertexBuffers,
+		vertexIndices: make(map[obj.Reference]uint32),
+	}
+}
+
+type bufferBuilder struct {
+	model         *obj.Model
+	options       BufferOptions
+	vertexBuffers []VertexBuffer
+	vertexIndices map[obj.Reference]uint32
+	indices       []uint32
+	ranges        []IndexRange
+}
+
+func (b *bufferBuilder) addMesh(mesh *obj.Mesh) {
+	indexOffset := len(b.indices)
+	for _, triangle := range TriangulateMesh(b.model, mesh) {
+		for _, ref := range triangle.References {
+			b.indices = append(b.indices, b.vertexIndex(ref))
+		}
+	}
+	b.ranges = append(b.ranges, IndexRange{
+		MaterialName: mesh.MaterialName,
+		IndexOffset:  indexOffset,
+		IndexCount:   len(b.indices) - indexOffset,
+	})
+}
+
+func (b *bufferBuilder) vertexIndex(ref obj.Reference) uint32 {
+	key := b.referenceKey(ref)
+	if index, ok := b.vertexIndices[key]; ok {
+		return index
+	}
+	index := uint32(len(b.vertexIndices))
+	b.vertexIndices[key] = index
+	for i, attribute := range b.options.Attributes {
+		bufferIndex := 0
+		if b.options.Layout == LayoutPlanar {
+			bufferIndex = i
+		}
+		buffer := &b.vertexBuffers[bufferIndex]
+		buffer.Data = b.appendAttribute(buffer.Data, attribute, ref)
+	}
+	return index
+}
+
+// referenceKey returns a version of the Reference that only retains
+// the indices that are relevant to the requested attributes.
+func (b *bufferBuilder) referenceKey(ref obj.Reference) obj.Reference {
+	key := obj.Reference{
+		VertexIndex:   obj.UndefinedIndex,
+		TexCoordIndex: obj.UndefinedIndex,
+		NormalIndex:   obj.UndefinedIndex,
+	}
+	for _, attribute := range b.options.Attributes {
+		switch attribute {
+		case AttributePosition, AttributeColor:
+			key.VertexIndex = ref.VertexIndex
+		case AttributeTexCoord:
+			key.TexCoordIndex = ref.TexCoordIndex
+		case AttributeNormal:
+			key.NormalIndex = ref.NormalIndex
+		}
+	}
+	return key
+}
+
+func (b *bufferBuilder) appendAttribute(data []float32, attribute Attribute, ref obj.Reference) []float32 {
+	switch attribute {
+	case AttributePosition:
+		vertex := b.model.GetVertexFromReference(ref)
+		return append(data, float32(vertex.X), float32(vertex.Y), float32(vertex.Z))
+	case AttributeTexCoord:
+		if !ref.HasTexCoord() {
+			return append(data, 0.0, 0.0)
+		}
+		texCoord := b.model.GetTexCoordFromReference(ref)
+		return append(data, float32(texCoord.U), float32(texCoord.V))
+	case AttributeNormal:
+		if !ref.HasNormal() {
+			return append(data, 0.0, 0.0, 0.0)
+		}
+		normal := b.model.GetNormalFromReference(ref)
+		return append(data, float32(normal.X), float32(normal.Y), float32(normal.Z))
+	case AttributeColor:
+		vertex := b.model.GetVertexFromReference(ref)
+		if !vertex.HasColor {
+			return append(data, 1.0, 1.0, 1.0)
+		}
+		return append(data, float32(vertex.R), float32(vertex.G), float32(vertex.B))
+	default:
+		return data
+	}
+}
+
+func (b *bufferBuilder) build() *Buffers {
+	buffers := &Buffers{
+		VertexCount:   len(b.vertexIndices),
+		VertexBuffers: b.vertexBuffers,
+		Ranges:        b.ranges,
+	}
+	// The largest 16 bit index is reserved for primitive restart
+	// by some graphics APIs (e.g. WebGL2), so it is never used.
+	if buffers.VertexCount <= math.MaxUint16 {
+		buffers.IndexFormat = IndexFormatUint16
+		buffers.Indices16 = make([]uint16, len(b.indices))
+		for i, index := range b.indices {
+			buffers.Indices16[i] = uint16(index)
+		}
+	} else {
+		buffers.IndexFormat = IndexFormatUint32
+		buffers.Indices32 = b.indices
+	}
+	return buffers
+}
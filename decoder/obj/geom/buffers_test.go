@@ -0,0 +1,329 @@
+package geom_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+	"github.com/mokiat/go-data-front/decoder/obj/geom"
+)
+
+var _ = Describe("DefaultBufferOptions", func() {
+	var options geom.BufferOptions
+
+	BeforeEach(func() {
+		options = geom.DefaultBufferOptions()
+	})
+
+	Specify("Attributes", func() {
+		Expect(options.Attributes).To(Equal([]geom.Attribute{
+			geom.AttributePosition,
+			geom.AttributeTexCoord,
+			geom.AttributeNormal,
+		}))
+	})
+
+	Specify("Layout", func() {
+		Expect(options.Layout).To(Equal(geom.LayoutInterleaved))
+	})
+})
+
+var _ = Describe("Attribute", func() {
+	Specify("ComponentCount", func() {
+		Expect(geom.AttributePosition.ComponentCount()).To(Equal(3))
+		Expect(geom.AttributeTexCoord.ComponentCount()).To(Equal(2))
+		Expect(geom.AttributeNormal.ComponentCount()).To(Equal(3))
+		Expect(geom.AttributeColor.ComponentCount()).To(Equal(3))
+		Expect(geom.Attribute(99).ComponentCount()).To(Equal(0))
+	})
+})
+
+var _ = Describe("Flattening", func() {
+	var (
+		model   *obj.Model
+		mesh    *obj.Mesh
+		options geom.BufferOptions
+		buffers *geom.Buffers
+	)
+
+	BeforeEach(func() {
+		model = &obj.Model{
+			Vertices: testVertices(
+				0, 0, 0,
+				1, 0, 0,
+				1, 1, 0,
+				0, 1, 0,
+			),
+			TexCoords: []obj.TexCoord{
+				{U: 0.0, V: 0.0},
+				{U: 1.0, V: 0.0},
+				{U: 1.0, V: 1.0},
+				{U: 0.0, V: 1.0},
+			},
+			Normals: []obj.Normal{
+				{X: 0.0, Y: 0.0, Z: 1.0},
+			},
+		}
+		mesh = &obj.Mesh{
+			MaterialName: "Red",
+			Faces: []*obj.Face{
+				{References: []obj.Reference{
+					{VertexIndex: 0, TexCoordIndex: 0, NormalIndex: 0},
+					{VertexIndex: 1, TexCoordIndex: 1, NormalIndex: 0},
+					{VertexIndex: 2, TexCoordIndex: 2, NormalIndex: 0},
+				}},
+				{References: []obj.Reference{
+					{VertexIndex: 0, TexCoordIndex: 0, NormalIndex: 0},
+					{VertexIndex: 2, TexCoordIndex: 2, NormalIndex: 0},
+					{VertexIndex: 3, TexCoordIndex: 3, NormalIndex: 0},
+				}},
+			},
+		}
+		options = geom.DefaultBufferOptions()
+	})
+
+	JustBeforeEach(func() {
+		buffers = geom.FlattenMesh(model, mesh, options)
+	})
+
+	It("should have merged the shared references", func() {
+		Expect(buffers.VertexCount).To(Equal(4))
+	})
+
+	It("should have used 16 bit indices", func() {
+		Expect(buffers.IndexFormat).To(Equal(geom.IndexFormatUint16))
+		Expect(buffers.Indices16).To(Equal([]uint16{0, 1, 2, 0, 2, 3}))
+		Expect(buffers.Indices32).To(BeEmpty())
+		Expect(buffers.IndexCount()).To(Equal(6))
+	})
+
+	It("should have a single range", func() {
+		Expect(buffers.Ranges).To(Equal([]geom.IndexRange{
+			{MaterialName: "Red", IndexOffset: 0, IndexCount: 6},
+		}))
+	})
+
+	It("should have interleaved the attributes", func() {
+		Expect(buffers.VertexBuffers).To(HaveLen(1))
+		vertexBuffer := buffers.VertexBuffers[0]
+		Expect(vertexBuffer.Stride).To(Equal(8))
+		Expect(vertexBuffer.Data).To(Equal([]float32{
+			0, 0, 0, 0, 0, 0, 0, 1,
+			1, 0, 0, 1, 0, 0, 0, 1,
+			1, 1, 0, 1, 1, 0, 0, 1,
+			0, 1, 0, 0, 1, 0, 0, 1,
+		}))
+
+		offset, ok := vertexBuffer.Offset(geom.AttributeNormal)
+		Expect(ok).To(BeTrue())
+		Expect(offset).To(Equal(5))
+
+		_, ok = vertexBuffer.Offset(geom.AttributeColor)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should not be affected by later changes to the options", func() {
+		options.Attributes[0] = geom.AttributeColor
+		Expect(buffers.VertexBuffers[0].Attributes).To(Equal([]geom.Attribute{
+			geom.AttributePosition,
+			geom.AttributeTexCoord,
+			geom.AttributeNormal,
+		}))
+	})
+
+	When("a planar layout is requested", func() {
+		BeforeEach(func() {
+			options.Layout = geom.LayoutPlanar
+			options.Attributes = []geom.Attribute{
+				geom.AttributeNormal,
+				geom.AttributePosition,
+			}
+		})
+
+		It("should have a buffer per attribute", func() {
+			Expect(buffers.VertexBuffers).To(Equal([]geom.VertexBuffer{
+				{
+					Attributes: []geom.Attribute{geom.AttributeNormal},
+					Stride:     3,
+					Data: []float32{
+						0, 0, 1,
+						0, 0, 1,
+						0, 0, 1,
+						0, 0, 1,
+					},
+				},
+				{
+					Attributes: []geom.Attribute{geom.AttributePosition},
+					Stride:     3,
+					Data: []float32{
+						0, 0, 0,
+						1, 0, 0,
+						1, 1, 0,
+						0, 1, 0,
+					},
+				},
+			}))
+		})
+	})
+
+	When("an unknown attribute is requested", func() {
+		BeforeEach(func() {
+			options.Attributes = []geom.Attribute{
+				geom.AttributePosition,
+				geom.Attribute(99),
+				geom.AttributeNormal,
+			}
+		})
+
+		It("should have stored no values for it", func() {
+			vertexBuffer := buffers.VertexBuffers[0]
+			Expect(vertexBuffer.Stride).To(Equal(6))
+			Expect(vertexBuffer.Data).To(HaveLen(4 * vertexBuffer.Stride))
+
+			offset, ok := vertexBuffer.Offset(geom.AttributeNormal)
+			Expect(ok).To(BeTrue())
+			Expect(offset).To(Equal(3))
+		})
+	})
+
+	When("references differ only in attributes that are not requested", func() {
+		BeforeEach(func() {
+			mesh.Faces[1].References[0].TexCoordIndex = 3
+			options.Attributes = []geom.Attribute{
+				geom.AttributePosition,
+				geom.AttributeNormal,
+			}
+		})
+
+		It("should have merged the references", func() {
+			Expect(buffers.VertexCount).To(Equal(4))
+		})
+	})
+
+	When("references differ in attributes that are requested", func() {
+		BeforeEach(func() {
+			mesh.Faces[1].References[0].TexCoordIndex = 3
+		})
+
+		It("should not have merged the references", func() {
+			Expect(buffers.VertexCount).To(Equal(5))
+			Expect(buffers.Indices16).To(Equal([]uint16{0, 1, 2, 3, 2, 4}))
+		})
+	})
+
+	When("references are missing data", func() {
+		BeforeEach(func() {
+			model.Vertices[0].HasColor = false
+			model.Vertices[1].R, model.Vertices[1].G, model.Vertices[1].B = 0.5, 0.25, 0.0
+			model.Vertices[1].HasColor = true
+			mesh.Faces = mesh.Faces[:1]
+			mesh.Faces[0].References[0].TexCoordIndex = obj.UndefinedIndex
+			mesh.Faces[0].References[0].NormalIndex = obj.UndefinedIndex
+			options.Attributes = []geom.Attribute{
+				geom.AttributeTexCoord,
+				geom.AttributeNormal,
+				geom.AttributeColor,
+			}
+		})
+
+		It("should have used default values", func() {
+			Expect(buffers.VertexBuffers[0].Data).To(Equal([]float32{
+				0, 0, 0, 0, 0, 1, 1, 1,
+				1, 0, 0, 0, 1, 0.5, 0.25, 0,
+				1, 1, 0, 0, 1, 1, 1, 1,
+			}))
+		})
+	})
+
+	// useSeparateTriangles replaces the faces of the mesh with the
+	// specified number of triangles that share no vertices.
+	useSeparateTriangles := func(triangleCount int) {
+		model.Vertices = make([]obj.Vertex, triangleCount*3)
+		mesh.Faces = make([]*obj.Face, triangleCount)
+		for i := range mesh.Faces {
+			base := int64(i * 3)
+			model.Vertices[base+0] = obj.Vertex{X: float64(i), Y: 0.0, W: 1.0}
+			model.Vertices[base+1] = obj.Vertex{X: float64(i) + 1.0, Y: 0.0, W: 1.0}
+			model.Vertices[base+2] = obj.Vertex{X: float64(i), Y: 1.0, W: 1.0}
+			mesh.Faces[i] = testFace(base, base+1, base+2)
+		}
+	}
+
+	When("all 16 bit indices except the primitive restart one are needed", func() {
+		BeforeEach(func() {
+			useSeparateTriangles(21845) // 65535 vertices
+		})
+
+		It("should have used 16 bit indices", func() {
+			Expect(buffers.VertexCount).To(Equal(65535))
+			Expect(buffers.IndexFormat).To(Equal(geom.IndexFormatUint16))
+			Expect(buffers.Indices16).To(HaveLen(65535))
+			Expect(buffers.Indices16[65534]).To(Equal(uint16(65534)))
+		})
+	})
+
+	When("the primitive restart 16 bit index would be needed", func() {
+		BeforeEach(func() {
+			useSeparateTriangles(21845) // 65535 vertices
+			model.Vertices = append(model.Vertices, obj.Vertex{X: -1.0, Y: -1.0, W: 1.0})
+			mesh.Faces = append(mesh.Faces, testFace(0, 1, 65535))
+		})
+
+		It("should have used 32 bit indices", func() {
+			Expect(buffers.VertexCount).To(Equal(65536))
+			Expect(buffers.IndexFormat).To(Equal(geom.IndexFormatUint32))
+			Expect(buffers.Indices16).To(BeEmpty())
+			Expect(buffers.Indices32[65537]).To(Equal(uint32(65535)))
+		})
+	})
+
+	When("there are more vertices than 16 bit indices can address", func() {
+		BeforeEach(func() {
+			useSeparateTriangles(21846) // 65538 vertices
+		})
+
+		It("should have used 32 bit indices", func() {
+			Expect(buffers.VertexCount).To(Equal(65538))
+			Expect(buffers.IndexFormat).To(Equal(geom.IndexFormatUint32))
+			Expect(buffers.Indices16).To(BeEmpty())
+			Expect(buffers.Indices32).To(HaveLen(65538))
+			Expect(buffers.Indices32[65537]).To(Equal(uint32(65537)))
+			Expect(buffers.IndexCount()).To(Equal(65538))
+		})
+	})
+
+	Describe("Object", func() {
+		var objectBuffers *geom.Buffers
+
+		JustBeforeEach(func() {
+			object := &obj.Object{
+				Meshes: []*obj.Mesh{
+					mesh,
+					{
+						MaterialName: "Green",
+						Faces: []*obj.Face{
+							{References: []obj.Reference{
+								{VertexIndex: 0, TexCoordIndex: 0, NormalIndex: 0},
+								{VertexIndex: 2, TexCoordIndex: 2, NormalIndex: 0},
+								{VertexIndex: 1, TexCoordIndex: 1, NormalIndex: 0},
+							}},
+						},
+					},
+				},
+			}
+			objectBuffers = geom.FlattenObject(model, object, options)
+		})
+
+		It("should have shared the vertices between meshes", func() {
+			Expect(objectBuffers.VertexCount).To(Equal(4))
+			Expect(objectBuffers.Indices16).To(Equal([]uint16{0, 1, 2, 0, 2, 3, 0, 2, 1}))
+		})
+
+		It("should have a range per mesh", func() {
+			Expect(objectBuffers.Ranges).To(Equal([]geom.IndexRange{
+				{MaterialName: "Red", IndexOffset: 0, IndexCount: 6},
+				{MaterialName: "Green", IndexOffset: 6, IndexCount: 3},
+			}))
+		})
+	})
+})
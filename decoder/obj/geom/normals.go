@@ -0,0 +1,252 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+)
+
+// NormalMode specifies how normals are calculated.
+type NormalMode int
+
+const (
+	// NormalModeFlat specifies that each face uses its own normal
+	// for all of its references.
+	NormalModeFlat NormalMode = iota
+
+	// NormalModeSmooth specifies that the normals of all faces
+	// that share a vertex are averaged.
+	NormalModeSmooth
+
+	// NormalModeCrease specifies that the normals of faces that
+	// share a vertex are averaged only if the angle between the
+	// faces does not exceed the CreaseAngle of the NormalOptions.
+	NormalModeCrease
+)
+
+// NormalWeighting specifies how much the normal of a face
+// contributes to an averaged normal.
+type NormalWeighting int
+
+const (
+	// NormalWeightingArea specifies that the contribution of
+	// a face is proportional to its area.
+	NormalWeightingArea NormalWeighting = iota
+
+	// NormalWeightingAngle specifies that the contribution of
+	// a face is proportional to the angle of its corner at the
+	// shared vertex.
+	NormalWeightingAngle
+)
+
+// NormalOptions specifies how normals should be generated.
+type NormalOptions struct {
+
+	// Mode specifies how the normals are calculated.
+	Mode NormalMode
+
+	// Weighting specifies how face normals are averaged when
+	// NormalModeSmooth or NormalModeCrease is used.
+	Weighting NormalWeighting
+
+	// CreaseAngle specifies, in radians, the maximum angle between
+	// two faces for their normals to be averaged when
+	// NormalModeCrease is used.
+	CreaseAngle float64
+
+	// SmoothingGroups specifies whether normals should only be
+	// averaged between faces that are part of the same smoothing
+	// group, in which case faces that are not part of a smoothing
+	// group use flat normals.
+	//
+	// This has no effect on models in which no face is part of a
+	// smoothing group.
+	SmoothingGroups bool
+
+	// Overwrite specifies whether references that already have
+	// normal information should be assigned new normals as well.
+	Overwrite bool
+}
+
+// DefaultNormalOptions returns some default NormalOptions.
+// Users can take the result and modify specific parameters.
+func DefaultNormalOptions() NormalOptions {
+	return NormalOptions{
+		Mode:            NormalModeSmooth,
+		Weighting:       NormalWeightingAngle,
+		CreaseAngle:     math.Pi / 3.0,
+		SmoothingGroups: true,
+		Overwrite:       false,
+	}
+}
+
+// GenerateNormals calculates normals for the faces of the specified
+// Model. The normals are appended to the Normals of the Model and
+// the References of the faces are updated to point to them.
+//
+// Normals are only averaged between faces of the same Object that
+// reference the same Vertex. Existing normals are not removed, even
+// if they are no longer referenced. Faces that have no area get
+// zero normals, unless averaged with other faces.
+func GenerateNormals(model *obj.Model, options NormalOptions) {
+	generator := &normalGenerator{
+		model:         model,
+		options:       options,
+		minCosine:     math.Cos(options.CreaseAngle),
+		useGroups:     options.SmoothingGroups && hasSmoothingGroups(model),
+		normalIndices: make(map[obj.Normal]int64),
+	}
+	for _, object := range model.Objects {
+		generator.processObject(object)
+	}
+}
+
+func hasSmoothingGroups(model *obj.Model) bool {
+	for _, object := range model.Objects {
+		for _, mesh := range object.Meshes {
+			for _, face := range mesh.Faces {
+				if face.SmoothingGroup != obj.NoSmoothingGroup {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+type normalGenerator struct {
+	model         *obj.Model
+	options       NormalOptions
+	minCosine     float64
+	useGroups     bool
+	normalIndices map[obj.Normal]int64
+}
+
+// corner represents the contribution of a face to the normal
+// of one of its vertices.
+type corner struct {
+	normal vec3
+	weight float64
+}
+
+// cornerKey is used to determine which corners should have
+// their normals averaged.
+type cornerKey struct {
+	vertexIndex    int64
+	smoothingGroup int64
+}
+
+func (g *normalGenerator) processObject(object *obj.Object) {
+	var faces []*obj.Face
+	for _, mesh := range object.Meshes {
+		faces = append(faces, mesh.Faces...)
+	}
+
+	faceNormals := make([]vec3, len(faces))
+	faceAreas := make([]float64, len(faces))
+	for i, face := range faces {
+		normal := polygonNormal(g.facePositions(face))
+		faceNormals[i] = normal.normalized()
+		faceAreas[i] = normal.length() / 2.0
+	}
+
+	if g.options.Mode == NormalModeFlat {
+		for i, face := range faces {
+			for j := range face.References {
+				g.assignNormal(face, j, faceNormals[i])
+			}
+		}
+		return
+	}
+
+	corners := make(map[cornerKey][]corner)
+	for i, face := range faces {
+		if !g.isSmoothed(face) {
+			continue
+		}
+		positions := g.facePositions(face)
+		for j, ref := range face.References {
+			key := g.cornerKey(face, ref)
+			weight := faceAreas[i]
+			if g.options.Weighting == NormalWeightingAngle {
+				weight = cornerAngle(positions, j)
+			}
+			corners[key] = append(corners[key], corner{
+				normal: faceNormals[i],
+				weight: weight,
+			})
+		}
+	}
+
+	for i, face := range faces {
+		if !g.isSmoothed(face) {
+			for j := range face.References {
+				g.assignNormal(face, j, faceNormals[i])
+			}
+			continue
+		}
+		for j, ref := range face.References {
+			var normal vec3
+			for _, candidate := range corners[g.cornerKey(face, ref)] {
+				if g.options.Mode == NormalModeCrease && candidate.normal.dot(faceNormals[i]) < g.minCosine {
+					continue
+				}
+				normal = normal.add(candidate.normal.scale(candidate.weight))
+			}
+			g.assignNormal(face, j, normal.normalized())
+		}
+	}
+}
+
+func (g *normalGenerator) isSmoothed(face *obj.Face) bool {
+	return !g.useGroups || face.SmoothingGroup != obj.NoSmoothingGroup
+}
+
+func (g *normalGenerator) cornerKey(face *obj.Face, ref obj.Reference) cornerKey {
+	key := cornerKey{
+		vertexIndex:    ref.VertexIndex,
+		smoothingGroup: obj.NoSmoothingGroup,
+	}
+	if g.useGroups {
+		key.smoothingGroup = face.SmoothingGroup
+	}
+	return key
+}
+
+func (g *normalGenerator) facePositions(face *obj.Face) []vec3 {
+	positions := make([]vec3, len(face.References))
+	for i, ref := range face.References {
+		positions[i] = position(g.model, ref)
+	}
+	return positions
+}
+
+func (g *normalGenerator) assignNormal(face *obj.Face, index int, normal vec3) {
+	ref := &face.References[index]
+	if ref.HasNormal() && !g.options.Overwrite {
+		return
+	}
+	value := obj.Normal{
+		X: normal.x,
+		Y: normal.y,
+		Z: normal.z,
+	}
+	normalIndex, ok := g.normalIndices[value]
+	if !ok {
+		normalIndex = int64(len(g.model.Normals))
+		g.model.Normals = append(g.model.Normals, value)
+		g.normalIndices[value] = normalIndex
+	}
+	ref.NormalIndex = normalIndex
+}
+
+// cornerAngle returns the angle in radians between the two edges of
+// the specified polygon that meet at the corner with the specified
+// index.
+func cornerAngle(positions []vec3, index int) float64 {
+	count := len(positions)
+	current := positions[index]
+	previous := positions[(index+count-1)%count].sub(current).normalized()
+	next := positions[(index+1)%count].sub(current).normalized()
+	return math.Acos(max(-1.0, min(1.0, previous.dot(next))))
+}
@@ -0,0 +1,263 @@
+package geom_test
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+	"github.com/mokiat/go-data-front/decoder/obj/geom"
+)
+
+var _ = Describe("DefaultNormalOptions", func() {
+	var options geom.NormalOptions
+
+	BeforeEach(func() {
+		options = geom.DefaultNormalOptions()
+	})
+
+	Specify("Mode", func() {
+		Expect(options.Mode).To(Equal(geom.NormalModeSmooth))
+	})
+
+	Specify("Weighting", func() {
+		Expect(options.Weighting).To(Equal(geom.NormalWeightingAngle))
+	})
+
+	Specify("CreaseAngle", func() {
+		Expect(options.CreaseAngle).To(BeNumerically("~", math.Pi/3.0))
+	})
+
+	Specify("SmoothingGroups", func() {
+		Expect(options.SmoothingGroups).To(BeTrue())
+	})
+
+	Specify("Overwrite", func() {
+		Expect(options.Overwrite).To(BeFalse())
+	})
+})
+
+var _ = Describe("Normal generation", func() {
+	var (
+		model       *obj.Model
+		firstFace   *obj.Face
+		secondFace  *obj.Face
+		options     geom.NormalOptions
+		areaNormal  obj.Normal
+		angleNormal obj.Normal
+	)
+
+	// normalAt returns the normal that is assigned to the reference
+	// with the specified index of the face.
+	normalAt := func(face *obj.Face, index int) obj.Normal {
+		GinkgoHelper()
+		ref := face.References[index]
+		Expect(ref.HasNormal()).To(BeTrue())
+		return model.GetNormalFromReference(ref)
+	}
+
+	beNormal := func(expected obj.Normal) OmegaMatcher {
+		return SatisfyAll(
+			HaveField("X", BeNumerically("~", expected.X, 0.0001)),
+			HaveField("Y", BeNumerically("~", expected.Y, 0.0001)),
+			HaveField("Z", BeNumerically("~", expected.Z, 0.0001)),
+		)
+	}
+
+	itShouldHaveFlatNormals := func() {
+		GinkgoHelper()
+
+		It("should have assigned the face normals", func() {
+			for i := range 3 {
+				Expect(normalAt(firstFace, i)).To(beNormal(obj.Normal{Z: 1.0}))
+				Expect(normalAt(secondFace, i)).To(beNormal(obj.Normal{X: 1.0}))
+			}
+		})
+
+		It("should have shared identical normals", func() {
+			Expect(model.Normals).To(HaveLen(2))
+		})
+	}
+
+	itShouldHaveSmoothNormals := func(expected *obj.Normal) {
+		GinkgoHelper()
+
+		It("should have averaged the normals of shared vertices", func() {
+			Expect(normalAt(firstFace, 0)).To(beNormal(*expected))
+			Expect(normalAt(secondFace, 0)).To(beNormal(*expected))
+			Expect(normalAt(firstFace, 2)).To(Equal(normalAt(secondFace, 1)))
+		})
+
+		It("should have kept the face normals of unshared vertices", func() {
+			Expect(normalAt(firstFace, 1)).To(beNormal(obj.Normal{Z: 1.0}))
+			Expect(normalAt(secondFace, 2)).To(beNormal(obj.Normal{X: 1.0}))
+		})
+	}
+
+	BeforeEach(func() {
+		// Two faces that meet at a right angle, where the second
+		// face is twice as large as the first one. Both faces have
+		// a right angle at the first vertex.
+		model = &obj.Model{
+			Vertices: testVertices(
+				0, 0, 0,
+				1, 0, 0,
+				0, 1, 0,
+				0, 0, 2,
+			),
+		}
+		firstFace = testFace(0, 1, 2)
+		secondFace = testFace(0, 2, 3)
+		model.Objects = []*obj.Object{
+			{Meshes: []*obj.Mesh{
+				{Faces: []*obj.Face{firstFace, secondFace}},
+			}},
+		}
+		options = geom.DefaultNormalOptions()
+		areaNormal = obj.Normal{X: 2.0 / math.Sqrt(5.0), Z: 1.0 / math.Sqrt(5.0)}
+		angleNormal = obj.Normal{X: 1.0 / math.Sqrt(2.0), Z: 1.0 / math.Sqrt(2.0)}
+	})
+
+	JustBeforeEach(func() {
+		geom.GenerateNormals(model, options)
+	})
+
+	When("flat normals are requested", func() {
+		BeforeEach(func() {
+			options.Mode = geom.NormalModeFlat
+		})
+
+		itShouldHaveFlatNormals()
+	})
+
+	When("area weighted smooth normals are requested", func() {
+		BeforeEach(func() {
+			options.Mode = geom.NormalModeSmooth
+			options.Weighting = geom.NormalWeightingArea
+		})
+
+		itShouldHaveSmoothNormals(&areaNormal)
+	})
+
+	When("angle weighted smooth normals are requested", func() {
+		BeforeEach(func() {
+			options.Mode = geom.NormalModeSmooth
+			options.Weighting = geom.NormalWeightingAngle
+		})
+
+		itShouldHaveSmoothNormals(&angleNormal)
+
+		It("should have shared identical normals", func() {
+			Expect(model.Normals).To(HaveLen(4))
+		})
+	})
+
+	When("crease normals are requested", func() {
+		BeforeEach(func() {
+			options.Mode = geom.NormalModeCrease
+		})
+
+		Context("and the faces meet at a sharper angle", func() {
+			BeforeEach(func() {
+				options.CreaseAngle = math.Pi / 3.0
+			})
+
+			itShouldHaveFlatNormals()
+		})
+
+		Context("and the faces meet at a shallower angle", func() {
+			BeforeEach(func() {
+				options.CreaseAngle = math.Pi * 2.0 / 3.0
+			})
+
+			itShouldHaveSmoothNormals(&angleNormal)
+		})
+	})
+
+	When("the faces are in different smoothing groups", func() {
+		BeforeEach(func() {
+			firstFace.SmoothingGroup = 1
+			secondFace.SmoothingGroup = 2
+		})
+
+		itShouldHaveFlatNormals()
+
+		Context("and smoothing groups are ignored", func() {
+			BeforeEach(func() {
+				options.SmoothingGroups = false
+			})
+
+			itShouldHaveSmoothNormals(&angleNormal)
+		})
+	})
+
+	When("the faces are in the same smoothing group", func() {
+		BeforeEach(func() {
+			firstFace.SmoothingGroup = 1
+			secondFace.SmoothingGroup = 1
+		})
+
+		itShouldHaveSmoothNormals(&angleNormal)
+	})
+
+	When("only some faces are in a smoothing group", func() {
+		BeforeEach(func() {
+			firstFace.SmoothingGroup = 1
+		})
+
+		itShouldHaveFlatNormals()
+	})
+
+	When("the faces are in different objects", func() {
+		BeforeEach(func() {
+			model.Objects = []*obj.Object{
+				{Meshes: []*obj.Mesh{
+					{Faces: []*obj.Face{firstFace}},
+				}},
+				{Meshes: []*obj.Mesh{
+					{Faces: []*obj.Face{secondFace}},
+				}},
+			}
+		})
+
+		itShouldHaveFlatNormals()
+	})
+
+	When("some references already have normals", func() {
+		BeforeEach(func() {
+			model.Normals = []obj.Normal{
+				{Y: 1.0},
+			}
+			firstFace.References[1].NormalIndex = 0
+		})
+
+		It("should have kept the existing normals", func() {
+			Expect(model.Normals[0]).To(Equal(obj.Normal{Y: 1.0}))
+			Expect(firstFace.References[1].NormalIndex).To(Equal(int64(0)))
+		})
+
+		It("should have appended the new normals", func() {
+			Expect(model.Normals).To(HaveLen(4))
+			Expect(normalAt(firstFace, 0)).To(beNormal(angleNormal))
+			Expect(normalAt(secondFace, 2)).To(beNormal(obj.Normal{X: 1.0}))
+		})
+
+		Context("and overwriting is requested", func() {
+			BeforeEach(func() {
+				options.Overwrite = true
+			})
+
+			It("should have replaced the existing normals", func() {
+				Expect(normalAt(firstFace, 1)).To(beNormal(obj.Normal{Z: 1.0}))
+			})
+		})
+	})
+
+	It("should have preserved the other reference data", func() {
+		for i, index := range []int64{0, 1, 2} {
+			Expect(firstFace.References[i].VertexIndex).To(Equal(index))
+			Expect(firstFace.References[i].TexCoordIndex).To(Equal(obj.UndefinedIndex))
+		}
+	})
+})
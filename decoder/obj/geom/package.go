@@ -0,0 +1,6 @@
+// Package geom provides geometry helpers that operate on decoded
+// Wavefront OBJ models (see package obj).
+//
+// These helpers are meant to simplify the conversion of OBJ data
+// into a form that can be consumed by a renderer (e.g. a GPU).
+package geom
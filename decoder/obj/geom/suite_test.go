@@ -0,0 +1,13 @@
+package geom_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGeom(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OBJ Geometry Suite")
+}
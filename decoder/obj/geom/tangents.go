@@ -0,0 +1,288 @@
+package geom
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mokiat/go-data-front/common"
+	"github.com/mokiat/go-data-front/decoder/obj"
+)
+
+// Tangent represents the tangent space information of a single
+// reference.
+type Tangent struct {
+
+	// X coordinate of this tangent.
+	X float64
+
+	// Y coordinate of this tangent.
+	Y float64
+
+	// Z coordinate of this tangent.
+	Z float64
+
+	// W holds the handedness of the tangent space. It is equal to
+	// -1.0 when the texture is mirrored and 1.0 otherwise.
+	W float64
+}
+
+// Bitangent calculates the bitangent from this Tangent and the
+// Normal of the reference.
+func (t Tangent) Bitangent(normal obj.Normal) Bitangent {
+	n := vec3{x: normal.X, y: normal.Y, z: normal.Z}
+	b := n.cross(vec3{x: t.X, y: t.Y, z: t.Z}).scale(t.W)
+	return Bitangent{
+		X: b.x,
+		Y: b.y,
+		Z: b.z,
+	}
+}
+
+// Bitangent represents the direction in which the V texture
+// coordinate increases.
+type Bitangent struct {
+
+	// X coordinate of this bitangent.
+	X float64
+
+	// Y coordinate of this bitangent.
+	Y float64
+
+	// Z coordinate of this bitangent.
+	Z float64
+}
+
+// GenerateTangents calculates the tangents of all the references of
+// the faces in the specified Mesh. The result is indexed first by
+// the index of the face within the Faces of the Mesh and then by the
+// index of the reference within the References of the face.
+//
+// The calculation is MikkTSpace-like: faces are split and weighted
+// as in MikkTSpace, so the results usually match those of tools that
+// use it (e.g. Blender). Unlike MikkTSpace, which only averages the
+// corners that are connected through shared edges around a vertex,
+// all corners that have equal position, texture coordinate and
+// normal values and the same handedness are averaged. Results can
+// therefore differ at vertices where unconnected faces touch (e.g.
+// bowtie or non-manifold vertices). References for which a tangent
+// cannot be determined (e.g. when the texture coordinates of all of
+// the surrounding faces have no area) get a zero tangent.
+//
+// An error is returned if a reference does not have texture
+// coordinate or normal information.
+func GenerateTangents(model *obj.Model, mesh *obj.Mesh) ([][]Tangent, error) {
+	for i, face := range mesh.Faces {
+		for j, ref := range face.References {
+			if !ref.HasTexCoord() {
+				return nil, fmt.Errorf("%w: reference %d of face %d has no texture coordinate", common.ErrInvalid, j, i)
+			}
+			if !ref.HasNormal() {
+				return nil, fmt.Errorf("%w: reference %d of face %d has no normal", common.ErrInvalid, j, i)
+			}
+		}
+	}
+
+	generator := &tangentGenerator{
+		model: model,
+		sums:  make(map[tangentKey]vec3),
+	}
+	for i, face := range mesh.Faces {
+		for _, triangle := range tangentTriangles(model, face) {
+			generator.addTriangle(i, face, triangle)
+		}
+	}
+	return generator.tangents(mesh), nil
+}
+
+// tangentTriangles splits the specified face into triangles in the
+// same way that MikkTSpace does.
+func tangentTriangles(model *obj.Model, face *obj.Face) [][3]int {
+	if len(face.References) != 4 {
+		return TriangulateFace(model, face)
+	}
+
+	// Quads are split along the shorter diagonal in texture space,
+	// falling back to the shorter diagonal in model space.
+	refs := face.References
+	texCoordDistance02 := texCoordDistanceSqr(model, refs[0], refs[2])
+	texCoordDistance13 := texCoordDistanceSqr(model, refs[1], refs[3])
+	var splitAt02 bool
+	switch {
+	case texCoordDistance02 < texCoordDistance13:
+		splitAt02 = true
+	case texCoordDistance13 < texCoordDistance02:
+		splitAt02 = false
+	default:
+		diagonal02 := position(model, refs[2]).sub(position(model, refs[0]))
+		diagonal13 := position(model, refs[3]).sub(position(model, refs[1]))
+		splitAt02 = diagonal13.dot(diagonal13) >= diagonal02.dot(diagonal02)
+	}
+	if splitAt02 {
+		return [][3]int{{0, 1, 2}, {0, 2, 3}}
+	}
+	return [][3]int{{0, 1, 3}, {1, 2, 3}}
+}
+
+func texCoordDistanceSqr(model *obj.Model, first, second obj.Reference) float64 {
+	a := model.GetTexCoordFromReference(first)
+	b := model.GetTexCoordFromReference(second)
+	return (b.U-a.U)*(b.U-a.U) + (b.V-a.V)*(b.V-a.V)
+}
+
+// tangentKey identifies the corners whose tangents are averaged.
+// Connectivity is not taken into account (see GenerateTangents).
+type tangentKey struct {
+	position             vec3
+	normal               vec3
+	u                    float64
+	v                    float64
+	preservesOrientation bool
+}
+
+// tangentCorner holds the information that is needed to assign a
+// tangent to a reference once all triangles have been processed.
+type tangentCorner struct {
+	faceIndex  int
+	refIndex   int
+	key        tangentKey
+	degenerate bool
+}
+
+type tangentGenerator struct {
+	model   *obj.Model
+	sums    map[tangentKey]vec3
+	corners []tangentCorner
+}
+
+func (g *tangentGenerator) addTriangle(faceIndex int, face *obj.Face, triangle [3]int) {
+	var (
+		positions [3]vec3
+		texCoords [3]obj.TexCoord
+		normals   [3]vec3
+	)
+	for i, refIndex := range triangle {
+		ref := face.References[refIndex]
+		normal := g.model.GetNormalFromReference(ref)
+		positions[i] = position(g.model, ref)
+		texCoords[i] = g.model.GetTexCoordFromReference(ref)
+		normals[i] = vec3{x: normal.X, y: normal.Y, z: normal.Z}
+	}
+
+	d1 := positions[1].sub(positions[0])
+	d2 := positions[2].sub(positions[0])
+	u21, v21 := texCoords[1].U-texCoords[0].U, texCoords[1].V-texCoords[0].V
+	u31, v31 := texCoords[2].U-texCoords[0].U, texCoords[2].V-texCoords[0].V
+	signedArea := u21*v31 - v21*u31
+	preservesOrientation := signedArea > 0.0
+
+	direction := d1.scale(v31).sub(d2.scale(v21))
+	degenerate := !notZero(signedArea) || !notZero(direction.length())
+	if !degenerate {
+		sign := 1.0
+		if !preservesOrientation {
+			sign = -1.0
+		}
+		direction = direction.scale(sign / direction.length())
+	}
+
+	for i, refIndex := range triangle {
+		normal := unitOrSelf(normals[i])
+		key := tangentKey{
+			position:             positions[i],
+			normal:               normals[i],
+			u:                    texCoords[i].U,
+			v:                    texCoords[i].V,
+			preservesOrientation: preservesOrientation,
+		}
+		g.corners = append(g.corners, tangentCorner{
+			faceIndex:  faceIndex,
+			refIndex:   refIndex,
+			key:        key,
+			degenerate: degenerate,
+		})
+		if degenerate {
+			continue
+		}
+
+		tangent := unitOrSelf(projectOnPlane(direction, normal))
+		previous := unitOrSelf(projectOnPlane(positions[(i+2)%3].sub(positions[i]), normal))
+		next := unitOrSelf(projectOnPlane(positions[(i+1)%3].sub(positions[i]), normal))
+		angle := math.Acos(max(-1.0, min(1.0, previous.dot(next))))
+		g.sums[key] = g.sums[key].add(tangent.scale(angle))
+	}
+}
+
+func (g *tangentGenerator) tangents(mesh *obj.Mesh) [][]Tangent {
+	result := make([][]Tangent, len(mesh.Faces))
+	assigned := make([][]bool, len(mesh.Faces))
+	for i, face := range mesh.Faces {
+		result[i] = make([]Tangent, len(face.References))
+		assigned[i] = make([]bool, len(face.References))
+		for j := range result[i] {
+			result[i][j] = Tangent{W: 1.0}
+		}
+	}
+
+	for _, corner := range g.corners {
+		if corner.degenerate {
+			continue
+		}
+		result[corner.faceIndex][corner.refIndex] = g.tangent(corner.key)
+		assigned[corner.faceIndex][corner.refIndex] = true
+	}
+
+	// Corners of degenerate triangles take the tangent of an equal
+	// corner of a proper triangle, if there is one.
+	for _, corner := range g.corners {
+		if !corner.degenerate || assigned[corner.faceIndex][corner.refIndex] {
+			continue
+		}
+		for _, preservesOrientation := range []bool{true, false} {
+			key := corner.key
+			key.preservesOrientation = preservesOrientation
+			if _, ok := g.sums[key]; ok {
+				result[corner.faceIndex][corner.refIndex] = g.tangent(key)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func (g *tangentGenerator) tangent(key tangentKey) Tangent {
+	tangent := unitOrSelf(g.sums[key])
+	sign := 1.0
+	if !key.preservesOrientation {
+		sign = -1.0
+	}
+	return Tangent{
+		X: tangent.x,
+		Y: tangent.y,
+		Z: tangent.z,
+		W: sign,
+	}
+}
+
+// minFloat32 is the smallest positive normal float32 value, which
+// MikkTSpace uses to determine whether a value is zero.
+const minFloat32 = 0x1p-126
+
+func notZero(value float64) bool {
+	return math.Abs(value) > minFloat32
+}
+
+// unitOrSelf returns a unit length version of the vector, unless
+// the vector is zero, in which case it is returned as is.
+func unitOrSelf(v vec3) vec3 {
+	length := v.length()
+	if !notZero(length) {
+		return v
+	}
+	return v.scale(1.0 / length)
+}
+
+// projectOnPlane projects the vector onto the plane with the
+// specified unit normal.
+func projectOnPlane(v, normal vec3) vec3 {
+	return v.sub(normal.scale(normal.dot(v)))
+}
@@ -0,0 +1,229 @@
+package geom_test
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/go-data-front/common"
+	"github.com/mokiat/go-data-front/decoder/obj"
+	"github.com/mokiat/go-data-front/decoder/obj/geom"
+)
+
+var _ = Describe("Tangent", func() {
+	It("should calculate the bitangent", func() {
+		tangent := geom.Tangent{X: 1.0, W: 1.0}
+		Expect(tangent.Bitangent(obj.Normal{Z: 1.0})).To(Equal(geom.Bitangent{Y: 1.0}))
+	})
+
+	It("should take handedness into account", func() {
+		tangent := geom.Tangent{X: 1.0, W: -1.0}
+		Expect(tangent.Bitangent(obj.Normal{Z: 1.0})).To(Equal(geom.Bitangent{Y: -1.0}))
+	})
+})
+
+var _ = Describe("Tangent generation", func() {
+	var (
+		model       *obj.Model
+		mesh        *obj.Mesh
+		tangents    [][]geom.Tangent
+		generateErr error
+	)
+
+	beTangent := func(expected geom.Tangent) OmegaMatcher {
+		return SatisfyAll(
+			HaveField("X", BeNumerically("~", expected.X, 0.0001)),
+			HaveField("Y", BeNumerically("~", expected.Y, 0.0001)),
+			HaveField("Z", BeNumerically("~", expected.Z, 0.0001)),
+			HaveField("W", Equal(expected.W)),
+		)
+	}
+
+	// texturedFace creates a face where each reference uses the
+	// vertex, texture coordinate and normal with the same index.
+	texturedFace := func(indices ...int64) *obj.Face {
+		face := &obj.Face{}
+		for _, index := range indices {
+			face.References = append(face.References, obj.Reference{
+				VertexIndex:   index,
+				TexCoordIndex: index,
+				NormalIndex:   index,
+			})
+		}
+		return face
+	}
+
+	BeforeEach(func() {
+		model = &obj.Model{
+			Vertices: testVertices(
+				0, 0, 0,
+				1, 0, 0,
+				1, 1, 0,
+				0, 1, 0,
+			),
+			TexCoords: []obj.TexCoord{
+				{U: 0.0, V: 0.0},
+				{U: 1.0, V: 0.0},
+				{U: 1.0, V: 1.0},
+				{U: 0.0, V: 1.0},
+			},
+			Normals: []obj.Normal{
+				{Z: 1.0},
+				{Z: 1.0},
+				{Z: 1.0},
+				{Z: 1.0},
+			},
+		}
+		mesh = &obj.Mesh{
+			Faces: []*obj.Face{
+				texturedFace(0, 1, 2, 3),
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tangents, generateErr = geom.GenerateTangents(model, mesh)
+	})
+
+	It("should not have returned an error", func() {
+		Expect(generateErr).ToNot(HaveOccurred())
+	})
+
+	It("should have produced a tangent per reference", func() {
+		Expect(tangents).To(HaveLen(1))
+		Expect(tangents[0]).To(HaveLen(4))
+	})
+
+	It("should have produced tangents in the direction of U", func() {
+		for _, tangent := range tangents[0] {
+			Expect(tangent).To(beTangent(geom.Tangent{X: 1.0, W: 1.0}))
+		}
+	})
+
+	When("the texture is mirrored", func() {
+		BeforeEach(func() {
+			for i := range model.TexCoords {
+				model.TexCoords[i].U = -model.TexCoords[i].U
+			}
+		})
+
+		It("should have produced tangents with negative handedness", func() {
+			for _, tangent := range tangents[0] {
+				Expect(tangent).To(beTangent(geom.Tangent{X: -1.0, W: -1.0}))
+			}
+		})
+
+		It("should have kept the bitangent in the direction of V", func() {
+			bitangent := tangents[0][0].Bitangent(obj.Normal{Z: 1.0})
+			Expect(bitangent.Y).To(BeNumerically("~", 1.0, 0.0001))
+		})
+	})
+
+	When("the texture is rotated", func() {
+		BeforeEach(func() {
+			for i, vertex := range model.Vertices {
+				model.TexCoords[i] = obj.TexCoord{U: vertex.Y, V: -vertex.X}
+			}
+		})
+
+		It("should have produced tangents in the direction of U", func() {
+			for _, tangent := range tangents[0] {
+				Expect(tangent).To(beTangent(geom.Tangent{Y: 1.0, W: 1.0}))
+			}
+		})
+	})
+
+	When("faces meet at an angle", func() {
+		BeforeEach(func() {
+			// A second face that continues the first one in the
+			// direction of U, but is bent downwards.
+			model.Vertices = append(model.Vertices, testVertices(
+				2, 0, -1,
+				2, 1, -1,
+			)...)
+			model.TexCoords = append(model.TexCoords,
+				obj.TexCoord{U: 2.0, V: 0.0},
+				obj.TexCoord{U: 2.0, V: 1.0},
+			)
+			sideNormal := obj.Normal{X: 1.0 / math.Sqrt(2.0), Z: 1.0 / math.Sqrt(2.0)}
+			model.Normals = append(model.Normals, sideNormal, sideNormal)
+			model.Normals[1] = obj.Normal{X: 0.3826834, Z: 0.9238795}
+			model.Normals[2] = model.Normals[1]
+			mesh.Faces = append(mesh.Faces, texturedFace(1, 4, 5, 2))
+		})
+
+		It("should have produced unit tangents that are perpendicular to the normals", func() {
+			for i, face := range mesh.Faces {
+				for j, ref := range face.References {
+					tangent := tangents[i][j]
+					normal := model.GetNormalFromReference(ref)
+					Expect(tangent.X*tangent.X + tangent.Y*tangent.Y + tangent.Z*tangent.Z).To(BeNumerically("~", 1.0, 0.0001))
+					Expect(tangent.X*normal.X + tangent.Y*normal.Y + tangent.Z*normal.Z).To(BeNumerically("~", 0.0, 0.0001))
+				}
+			}
+		})
+
+		It("should have produced equal tangents for shared references", func() {
+			Expect(tangents[0][1]).To(Equal(tangents[1][0]))
+			Expect(tangents[0][2]).To(Equal(tangents[1][3]))
+		})
+	})
+
+	When("a face has an n-gon shape", func() {
+		BeforeEach(func() {
+			model.Vertices = append(model.Vertices, testVertices(0.5, 1.5, 0)...)
+			model.TexCoords = append(model.TexCoords, obj.TexCoord{U: 0.5, V: 1.5})
+			model.Normals = append(model.Normals, obj.Normal{Z: 1.0})
+			mesh.Faces = []*obj.Face{
+				texturedFace(0, 1, 2, 4, 3),
+			}
+		})
+
+		It("should have produced tangents for all references", func() {
+			Expect(tangents[0]).To(HaveLen(5))
+			for _, tangent := range tangents[0] {
+				Expect(tangent).To(beTangent(geom.Tangent{X: 1.0, W: 1.0}))
+			}
+		})
+	})
+
+	When("a face has texture coordinates with no area", func() {
+		BeforeEach(func() {
+			model.TexCoords[3] = obj.TexCoord{U: 0.5, V: 0.5}
+			mesh.Faces = []*obj.Face{
+				texturedFace(0, 1, 2),
+				texturedFace(0, 2, 3),
+			}
+		})
+
+		It("should have used the tangents of equal corners", func() {
+			Expect(tangents[1][0]).To(Equal(tangents[0][0]))
+			Expect(tangents[1][1]).To(Equal(tangents[0][2]))
+		})
+
+		It("should have used zero tangents for the remaining corners", func() {
+			Expect(tangents[1][2]).To(Equal(geom.Tangent{W: 1.0}))
+		})
+	})
+
+	When("a reference has no texture coordinate", func() {
+		BeforeEach(func() {
+			mesh.Faces[0].References[2].TexCoordIndex = obj.UndefinedIndex
+		})
+
+		It("should have returned an invalid error", func() {
+			Expect(generateErr).To(MatchError(common.ErrInvalid))
+		})
+	})
+
+	When("a reference has no normal", func() {
+		BeforeEach(func() {
+			mesh.Faces[0].References[1].NormalIndex = obj.UndefinedIndex
+		})
+
+		It("should have returned an invalid error", func() {
+			Expect(generateErr).To(MatchError(common.ErrInvalid))
+		})
+	})
+})
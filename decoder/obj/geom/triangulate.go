@@ -0,0 +1,246 @@
+package geom
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+)
+
+// Triangle represents a single triangle that was produced through
+// the triangulation of a Face.
+type Triangle struct {
+
+	// References holds the references of the three corners of
+	// the triangle. They follow the winding order of the source
+	// face.
+	References [3]obj.Reference
+
+	// Face holds the face from which this triangle originates.
+	Face *obj.Face
+
+	// FaceIndex holds the index of the source face within the
+	// Faces of the Mesh.
+	FaceIndex int
+
+	// Corners holds the indices into the References of the source
+	// face from which the corners of this triangle originate.
+	Corners [3]int
+}
+
+// TriangulateMesh splits all the faces of the specified Mesh into
+// triangles. Lines and points are ignored.
+//
+// See TriangulateFace for more information on how faces are split.
+func TriangulateMesh(model *obj.Model, mesh *obj.Mesh) []Triangle {
+	var triangles []Triangle
+	for faceIndex, face := range mesh.Faces {
+		for _, corners := range TriangulateFace(model, face) {
+			triangles = append(triangles, Triangle{
+				References: [3]obj.Reference{
+					face.References[corners[0]],
+					face.References[corners[1]],
+					face.References[corners[2]],
+				},
+				Face:      face,
+				FaceIndex: faceIndex,
+				Corners:   corners,
+			})
+		}
+	}
+	return triangles
+}
+
+// TriangulateFace splits the specified Face into triangles. Each
+// triangle is returned as three indices into the References of the
+// face and follows the winding order of the face.
+//
+// The face is projected onto its best-fit plane, which makes it
+// possible to handle non-planar faces, and is then split via ear
+// clipping, which makes it possible to handle concave faces.
+//
+// A face with N references (N >= 3) always results in N-2 triangles.
+// This means that degenerate faces (e.g. ones with collinear or
+// duplicate vertices) can produce triangles with zero area. Faces
+// with fewer than three references produce no triangles.
+func TriangulateFace(model *obj.Model, face *obj.Face) [][3]int {
+	count := len(face.References)
+	if count < 3 {
+		return nil
+	}
+	if count == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+
+	positions := make([]vec3, count)
+	for i, ref := range face.References {
+		positions[i] = position(model, ref)
+	}
+	normal := polygonNormal(positions).normalized()
+	if normal == (vec3{}) {
+		// The face has no area, so any split is as good as another.
+		return fanTriangles(count)
+	}
+	return clipEars(projectPolygon(positions, normal))
+}
+
+// point2 is an internal two-dimensional point representation
+// that is used for calculations in the plane of a face.
+type point2 struct {
+	x float64
+	y float64
+}
+
+// polygonNormal calculates the normal of the best-fit plane of
+// the specified polygon via Newell's method. The length of the
+// result is equal to twice the area of the (planar) polygon.
+func polygonNormal(positions []vec3) vec3 {
+	var normal vec3
+	for i, current := range positions {
+		next := positions[(i+1)%len(positions)]
+		normal = normal.add(current.cross(next))
+	}
+	return normal
+}
+
+// projectPolygon projects the specified polygon onto the plane
+// with the specified unit normal. The resulting polygon has a
+// counter-clockwise winding order.
+func projectPolygon(positions []vec3, normal vec3) []point2 {
+	axis := vec3{x: 1.0}
+	if math.Abs(normal.x) > 0.9 {
+		axis = vec3{y: 1.0}
+	}
+	u := axis.cross(normal).normalized()
+	v := normal.cross(u)
+
+	points := make([]point2, len(positions))
+	for i, position := range positions {
+		points[i] = point2{
+			x: position.dot(u),
+			y: position.dot(v),
+		}
+	}
+	return points
+}
+
+// fanTriangles splits a polygon with the specified number of
+// corners into triangles that share the first corner.
+func fanTriangles(count int) [][3]int {
+	triangles := make([][3]int, 0, count-2)
+	for i := 1; i < count-1; i++ {
+		triangles = append(triangles, [3]int{0, i, i + 1})
+	}
+	return triangles
+}
+
+// clipEars splits the specified counter-clockwise polygon into
+// triangles via ear clipping.
+func clipEars(points []point2) [][3]int {
+	tolerance := areaTolerance(points)
+
+	remaining := make([]int, len(points))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	triangles := make([][3]int, 0, len(points)-2)
+	for len(remaining) > 3 {
+		index := findEar(points, remaining, tolerance)
+		if index < 0 {
+			// The polygon is degenerate or self-intersecting,
+			// so clip the corner that does the least harm.
+			index = findFallbackEar(points, remaining, tolerance)
+		}
+		count := len(remaining)
+		triangles = append(triangles, [3]int{
+			remaining[(index+count-1)%count],
+			remaining[index],
+			remaining[(index+1)%count],
+		})
+		remaining = slices.Delete(remaining, index, index+1)
+	}
+	return append(triangles, [3]int{remaining[0], remaining[1], remaining[2]})
+}
+
+// findEar returns the position within remaining of a corner that
+// forms a convex triangle with its neighbours which does not
+// contain any other corner, or -1 if there is no such corner.
+func findEar(points []point2, remaining []int, tolerance float64) int {
+	count := len(remaining)
+	for i := range remaining {
+		a := points[remaining[(i+count-1)%count]]
+		b := points[remaining[i]]
+		c := points[remaining[(i+1)%count]]
+		if triangleArea2(a, b, c) <= tolerance {
+			continue
+		}
+		if !containsAnyPoint(points, remaining, a, b, c, tolerance) {
+			return i
+		}
+	}
+	return -1
+}
+
+// findFallbackEar returns the position within remaining of the
+// corner that should be clipped when there are no proper ears.
+//
+// Corners that are collinear with their neighbours are preferred,
+// since clipping them does not change the shape of the polygon.
+// Otherwise, the most convex corner is chosen.
+func findFallbackEar(points []point2, remaining []int, tolerance float64) int {
+	count := len(remaining)
+	bestIndex := 0
+	bestArea := math.Inf(-1)
+	for i := range remaining {
+		a := points[remaining[(i+count-1)%count]]
+		b := points[remaining[i]]
+		c := points[remaining[(i+1)%count]]
+		area := triangleArea2(a, b, c)
+		if math.Abs(area) <= tolerance {
+			return i
+		}
+		if area > bestArea {
+			bestIndex = i
+			bestArea = area
+		}
+	}
+	return bestIndex
+}
+
+// containsAnyPoint checks whether any of the remaining points,
+// other than the ones that coincide with the corners of the
+// triangle a, b, c, lies inside or on the edge of the triangle.
+func containsAnyPoint(points []point2, remaining []int, a, b, c point2, tolerance float64) bool {
+	for _, index := range remaining {
+		p := points[index]
+		if p == a || p == b || p == c {
+			continue
+		}
+		if triangleArea2(a, b, p) >= -tolerance &&
+			triangleArea2(b, c, p) >= -tolerance &&
+			triangleArea2(c, a, p) >= -tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// triangleArea2 returns twice the signed area of the triangle
+// a, b, c. The result is positive for counter-clockwise triangles.
+func triangleArea2(a, b, c point2) float64 {
+	return (b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x)
+}
+
+// areaTolerance returns the area below which a triangle within
+// the specified polygon is considered degenerate.
+func areaTolerance(points []point2) float64 {
+	minX, maxX := points[0].x, points[0].x
+	minY, maxY := points[0].y, points[0].y
+	for _, p := range points[1:] {
+		minX, maxX = min(minX, p.x), max(maxX, p.x)
+		minY, maxY = min(minY, p.y), max(maxY, p.y)
+	}
+	extent := max(maxX-minX, maxY-minY)
+	return 1e-10 * extent * extent
+}
@@ -0,0 +1,278 @@
+package geom_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+	"github.com/mokiat/go-data-front/decoder/obj/geom"
+)
+
+var _ = Describe("Triangulation", func() {
+	var (
+		model     *obj.Model
+		face      *obj.Face
+		triangles [][3]int
+	)
+
+	// triangleArea returns the signed area of the specified triangle
+	// when viewed from the positive Z axis.
+	triangleArea := func(triangle [3]int) float64 {
+		a := model.GetVertexFromReference(face.References[triangle[0]])
+		b := model.GetVertexFromReference(face.References[triangle[1]])
+		c := model.GetVertexFromReference(face.References[triangle[2]])
+		return ((b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)) / 2.0
+	}
+
+	// canonical rotates the triangle so that it starts with its
+	// lowest index, without changing its winding order.
+	canonical := func(triangle [3]int) [3]int {
+		for triangle[0] > triangle[1] || triangle[0] > triangle[2] {
+			triangle = [3]int{triangle[1], triangle[2], triangle[0]}
+		}
+		return triangle
+	}
+
+	canonicalTriangles := func() [][3]int {
+		result := make([][3]int, len(triangles))
+		for i, triangle := range triangles {
+			result[i] = canonical(triangle)
+		}
+		return result
+	}
+
+	itShouldCoverTheFace := func(expectedArea float64) {
+		GinkgoHelper()
+
+		It("should produce N-2 triangles", func() {
+			Expect(triangles).To(HaveLen(len(face.References) - 2))
+		})
+
+		It("should produce triangles with the winding order of the face", func() {
+			for _, triangle := range triangles {
+				Expect(triangleArea(triangle)).To(BeNumerically(">", 0.0))
+			}
+		})
+
+		It("should produce triangles that cover the face", func() {
+			var area float64
+			for _, triangle := range triangles {
+				area += triangleArea(triangle)
+			}
+			Expect(area).To(BeNumerically("~", expectedArea, 0.0001))
+		})
+	}
+
+	BeforeEach(func() {
+		model = &obj.Model{}
+	})
+
+	JustBeforeEach(func() {
+		triangles = geom.TriangulateFace(model, face)
+	})
+
+	When("the face has fewer than three references", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 1, 0, 0)
+			face = testFace(0, 1)
+		})
+
+		It("should produce no triangles", func() {
+			Expect(triangles).To(BeEmpty())
+		})
+	})
+
+	When("the face is a triangle", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 1, 0, 0, 0, 1, 0)
+			face = testFace(0, 1, 2)
+		})
+
+		It("should produce the same triangle", func() {
+			Expect(triangles).To(Equal([][3]int{{0, 1, 2}}))
+		})
+	})
+
+	When("the face is a convex quad", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 2, 0, 0, 2, 2, 0, 0, 2, 0)
+			face = testFace(0, 1, 2, 3)
+		})
+
+		itShouldCoverTheFace(4.0)
+	})
+
+	When("the face is a concave quad", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 4, 0, 0, 1, 1, 0, 0, 4, 0)
+			face = testFace(0, 1, 2, 3)
+		})
+
+		itShouldCoverTheFace(4.0)
+
+		It("should split the face through the reflex corner", func() {
+			Expect(canonicalTriangles()).To(ConsistOf(
+				[3]int{0, 1, 2},
+				[3]int{0, 2, 3},
+			))
+		})
+	})
+
+	When("the face is a concave quad in a different plane", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 0, 0, 4, 0, 1, 1, 0, 4, 0)
+			face = testFace(0, 1, 2, 3)
+		})
+
+		It("should split the face through the reflex corner", func() {
+			Expect(canonicalTriangles()).To(ConsistOf(
+				[3]int{0, 1, 2},
+				[3]int{0, 2, 3},
+			))
+		})
+	})
+
+	When("the face is a concave n-gon", func() {
+		BeforeEach(func() {
+			// An L-shaped polygon
+			model.Vertices = testVertices(
+				0, 0, 0,
+				2, 0, 0,
+				2, 1, 0,
+				1, 1, 0,
+				1, 2, 0,
+				0, 2, 0,
+			)
+			face = testFace(0, 1, 2, 3, 4, 5)
+		})
+
+		itShouldCoverTheFace(3.0)
+	})
+
+	When("the face has collinear vertices", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 1, 0, 0, 2, 0, 0, 2, 2, 0, 0, 2, 0)
+			face = testFace(0, 1, 2, 3, 4)
+		})
+
+		itShouldCoverTheFace(4.0)
+	})
+
+	When("the face is slightly non-planar", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 4, 0, 0.01, 1, 1, -0.01, 0, 4, 0.01)
+			face = testFace(0, 1, 2, 3)
+		})
+
+		It("should split the face through the reflex corner", func() {
+			Expect(canonicalTriangles()).To(ConsistOf(
+				[3]int{0, 1, 2},
+				[3]int{0, 2, 3},
+			))
+		})
+	})
+
+	When("the face has no area", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 1, 0, 0, 2, 0, 0, 3, 0, 0)
+			face = testFace(0, 1, 2, 3)
+		})
+
+		It("should still produce N-2 triangles", func() {
+			Expect(triangles).To(HaveLen(2))
+		})
+	})
+
+	When("the face has duplicate vertices", func() {
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 2, 0, 0, 2, 2, 0, 0, 2, 0)
+			face = testFace(0, 1, 1, 2, 3)
+		})
+
+		It("should produce N-2 triangles", func() {
+			Expect(triangles).To(HaveLen(3))
+		})
+
+		It("should produce triangles that cover the face", func() {
+			var area float64
+			for _, triangle := range triangles {
+				Expect(triangleArea(triangle)).To(BeNumerically(">=", 0.0))
+				area += triangleArea(triangle)
+			}
+			Expect(area).To(BeNumerically("~", 4.0, 0.0001))
+		})
+	})
+
+	Describe("Mesh", func() {
+		var (
+			mesh          *obj.Mesh
+			meshTriangles []geom.Triangle
+		)
+
+		BeforeEach(func() {
+			model.Vertices = testVertices(0, 0, 0, 2, 0, 0, 2, 2, 0, 0, 2, 0)
+			mesh = &obj.Mesh{
+				Faces: []*obj.Face{
+					testFace(0, 1, 2),
+					{References: []obj.Reference{
+						{VertexIndex: 0, TexCoordIndex: 5, NormalIndex: 6},
+						{VertexIndex: 2, TexCoordIndex: 7, NormalIndex: 8},
+						{VertexIndex: 3, TexCoordIndex: 9, NormalIndex: 10},
+					}},
+				},
+			}
+			face = mesh.Faces[0]
+		})
+
+		JustBeforeEach(func() {
+			meshTriangles = geom.TriangulateMesh(model, mesh)
+		})
+
+		It("should produce triangles that map back to the source faces", func() {
+			Expect(meshTriangles).To(HaveLen(2))
+			Expect(meshTriangles[0].Face).To(BeIdenticalTo(mesh.Faces[0]))
+			Expect(meshTriangles[0].FaceIndex).To(Equal(0))
+			Expect(meshTriangles[1].Face).To(BeIdenticalTo(mesh.Faces[1]))
+			Expect(meshTriangles[1].FaceIndex).To(Equal(1))
+			Expect(meshTriangles[1].Corners).To(Equal([3]int{0, 1, 2}))
+		})
+
+		It("should preserve the reference data", func() {
+			Expect(meshTriangles[1].References).To(Equal([3]obj.Reference{
+				{VertexIndex: 0, TexCoordIndex: 5, NormalIndex: 6},
+				{VertexIndex: 2, TexCoordIndex: 7, NormalIndex: 8},
+				{VertexIndex: 3, TexCoordIndex: 9, NormalIndex: 10},
+			}))
+		})
+	})
+})
+
+// testVertices creates vertices out of consecutive X, Y, Z coordinates.
+func testVertices(coords ...float64) []obj.Vertex {
+	vertices := make([]obj.Vertex, len(coords)/3)
+	for i := range vertices {
+		vertices[i] = obj.Vertex{
+			X: coords[i*3+0],
+			Y: coords[i*3+1],
+			Z: coords[i*3+2],
+			W: 1.0,
+		}
+	}
+	return vertices
+}
+
+// testFace creates a face that references only the vertices with
+// the specified indices.
+func testFace(indices ...int64) *obj.Face {
+	face := &obj.Face{
+		SmoothingGroup: obj.NoSmoothingGroup,
+	}
+	for _, index := range indices {
+		face.References = append(face.References, obj.Reference{
+			VertexIndex:   index,
+			TexCoordIndex: obj.UndefinedIndex,
+			NormalIndex:   obj.UndefinedIndex,
+		})
+	}
+	return face
+}
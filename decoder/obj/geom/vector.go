@@ -0,0 +1,64 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/mokiat/go-data-front/decoder/obj"
+)
+
+// vec3 is an internal three-dimensional vector representation
+// that is used for geometry calculations.
+type vec3 struct {
+	x float64
+	y float64
+	z float64
+}
+
+func (v vec3) add(other vec3) vec3 {
+	return vec3{x: v.x + other.x, y: v.y + other.y, z: v.z + other.z}
+}
+
+func (v vec3) sub(other vec3) vec3 {
+	return vec3{x: v.x - other.x, y: v.y - other.y, z: v.z - other.z}
+}
+
+func (v vec3) scale(amount float64) vec3 {
+	return vec3{x: v.x * amount, y: v.y * amount, z: v.z * amount}
+}
+
+func (v vec3) dot(other vec3) float64 {
+	return v.x*other.x + v.y*other.y + v.z*other.z
+}
+
+func (v vec3) cross(other vec3) vec3 {
+	return vec3{
+		x: v.y*other.z - v.z*other.y,
+		y: v.z*other.x - v.x*other.z,
+		z: v.x*other.y - v.y*other.x,
+	}
+}
+
+func (v vec3) length() float64 {
+	return math.Sqrt(v.dot(v))
+}
+
+// normalized returns a unit length version of the vector or
+// a zero vector if the vector is too short to be normalized.
+func (v vec3) normalized() vec3 {
+	length := v.length()
+	if length < epsilon {
+		return vec3{}
+	}
+	return v.scale(1.0 / length)
+}
+
+// epsilon is used to determine whether a length or an area
+// is small enough to be considered degenerate.
+const epsilon = 1e-12
+
+// position returns the position of the Vertex that is pointed
+// to by the specified Reference.
+func position(model *obj.Model, ref obj.Reference) vec3 {
+	vertex := model.GetVertexFromReference(ref)
+	return vec3{x: vertex.X, y: vertex.Y, z: vertex.Z}
+}
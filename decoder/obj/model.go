@@ -15,10 +15,24 @@ type Model struct {
 	// Objects holds a list of all the objects
 	Objects []*Object
 
+	// Groups holds a list of all the groups
+	Groups []*Group
+
 	// MaterialLibraries holds a list of filenames to MTL
 	// resources that should be used together with the current
 	// OBJ resource
 	MaterialLibraries []string
+
+	// UnknownCommands holds a list of all the commands that
+	// are not supported by the decoder, in the order in which
+	// they were declared.
+	//
+	// Only the order among the unknown commands themselves is
+	// kept. Their position relative to other declarations is not
+	// recorded, so commands that depend on it (e.g. ZBrush `#MRGB`
+	// blocks, which apply to the preceding vertices) lose their
+	// meaning when the Model is encoded again.
+	UnknownCommands []UnknownCommand
 }
 
 // GetVertexFromReference is a helper method that allows one
@@ -50,6 +64,17 @@ func (m *Model) FindObject(name string) (*Object, bool) {
 	return nil, false
 }
 
+// FindGroup is a helper method that allows one to search
+// for a group in this model based on name
+func (m *Model) FindGroup(name string) (*Group, bool) {
+	for _, group := range m.Groups {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return nil, false
+}
+
 // Vertex is used to define the positional
 // information for objects.
 type Vertex struct {
@@ -65,6 +90,20 @@ type Vertex struct {
 
 	// W coordinate of this vertex. (By default 1.0)
 	W float64
+
+	// R holds the red color component of this vertex.
+	R float64
+
+	// G holds the green color component of this vertex.
+	G float64
+
+	// B holds the blue color component of this vertex.
+	B float64
+
+	// HasColor specifies whether this vertex has color
+	// information. If false, then R, G, and B should be
+	// ignored.
+	HasColor bool
 }
 
 // Normal is used to define the directional
@@ -133,6 +172,26 @@ type Mesh struct {
 
 	// Faces holds all the faces that comprise this mesh
 	Faces []*Face
+
+	// Lines holds all the lines (polylines) that are part
+	// of this mesh
+	Lines []*Line
+
+	// Points holds all the points that are part of this mesh
+	//
+	// A single point declaration can specify multiple points,
+	// which is why these are not grouped by declaration.
+	Points []Reference
+}
+
+// Group represents a named group of elements in the model.
+//
+// Unlike objects, groups can overlap, since a single face
+// can belong to multiple groups at the same time.
+type Group struct {
+
+	// Name holds the name of the group
+	Name string
 }
 
 // Face defines a single face that is part of a mesh
@@ -144,8 +203,41 @@ type Face struct {
 	// point in space. The list of all references compose
 	// a polygon shape.
 	References []Reference
+
+	// Groups holds all the groups that this face is
+	// a member of. It is empty if the face was not
+	// declared within a group.
+	Groups []*Group
+
+	// SmoothingGroup holds the number of the smoothing
+	// group that this face belongs to. Faces that share
+	// a smoothing group should appear smooth where they
+	// meet.
+	//
+	// If this value is equal to NoSmoothingGroup, then
+	// the face does not belong to a smoothing group.
+	SmoothingGroup int64
+}
+
+// Line defines a single line that is part of a mesh
+type Line struct {
+
+	// References holds an array of Reference objects
+	//
+	// Each Reference holds information for a single
+	// point in space. Consecutive references are connected
+	// to form a polyline.
+	References []Reference
 }
 
+// DefaultObjectName is the name of the object that is used
+// for elements that are declared outside of any object.
+const DefaultObjectName = "Default"
+
+// NoSmoothingGroup is used to mark a face as not being
+// part of any smoothing group.
+const NoSmoothingGroup int64 = 0
+
 // UndefinedIndex is used to mark an index as undefined.
 const UndefinedIndex int64 = -1
 
@@ -188,3 +280,14 @@ func (r Reference) HasTexCoord() bool {
 func (r Reference) HasNormal() bool {
 	return r.NormalIndex != UndefinedIndex
 }
+
+// UnknownCommand represents a command that is not supported
+// by the decoder (e.g. a vendor-specific extension).
+type UnknownCommand struct {
+
+	// Name holds the name of the command.
+	Name string
+
+	// Params holds the raw parameters of the command.
+	Params []string
+}
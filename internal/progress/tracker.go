@@ -0,0 +1,103 @@
+// Package progress provides utilities for reporting the progress
+// of a Scanner through a Wavefront resource.
+package progress
+
+import (
+	"io"
+	"time"
+
+	"github.com/mokiat/go-data-front/common"
+)
+
+// Tracker keeps track of the progress of a scan and reports it
+// through the common.ProgressHandler of the common.ScanOptions.
+//
+// A Tracker for options without a handler does nothing.
+type Tracker struct {
+	handler    common.ProgressHandler
+	interval   time.Duration
+	progress   common.Progress
+	lastReport time.Time
+	reported   bool
+}
+
+// NewTracker creates a new Tracker for a scan through the specified
+// io.Reader, according to the specified common.ScanOptions.
+func NewTracker(reader io.Reader, options *common.ScanOptions) *Tracker {
+	if options.ProgressHandler == nil {
+		return &Tracker{}
+	}
+	totalBytes := options.TotalBytes
+	if totalBytes <= 0 {
+		totalBytes = remainingBytes(reader)
+	}
+	interval := options.ProgressInterval
+	if interval == 0 {
+		interval = common.DefaultProgressInterval
+	}
+	return &Tracker{
+		handler:  options.ProgressHandler,
+		interval: interval,
+		progress: common.Progress{
+			TotalBytes: totalBytes,
+		},
+		lastReport: time.Now(),
+	}
+}
+
+// LineScanned records that the specified logical line has been
+// processed.
+//
+// The consumed bytes are derived from the size of the line, since
+// the io.Reader is usually read ahead of the line being processed.
+func (t *Tracker) LineScanned(line common.Line) {
+	if t.handler == nil {
+		return
+	}
+	t.progress.BytesRead += int64(line.Size())
+	t.progress.Lines++
+	if line.IsCommand() && !line.IsComment() {
+		t.progress.Elements++
+	}
+	t.reported = false
+	if now := time.Now(); now.Sub(t.lastReport) >= t.interval {
+		t.report(now)
+	}
+}
+
+// Finish reports the final progress, unless it has already been
+// reported.
+func (t *Tracker) Finish() {
+	if t.handler == nil || t.reported {
+		return
+	}
+	t.report(time.Now())
+}
+
+func (t *Tracker) report(now time.Time) {
+	t.handler(t.progress)
+	t.lastReport = now
+	t.reported = true
+}
+
+// remainingBytes returns the number of bytes between the current
+// position of the specified reader and its end, if the reader is an
+// io.Seeker, or zero otherwise.
+func remainingBytes(reader io.Reader) int64 {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return 0
+	}
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0
+	}
+	if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+		return 0
+	}
+	return end - current
+}
@@ -0,0 +1,17 @@
+package testutil
+
+import "io"
+
+func NewFailingWriter(err error) io.Writer {
+	return &failingWriter{
+		err: err,
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (n int, err error) {
+	return 0, w.err
+}
@@ -1,17 +1,22 @@
 package mtl
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/mokiat/go-data-front/common"
+	"github.com/mokiat/go-data-front/internal/progress"
 )
 
 // MaterialEvent indicates that a material declaration (`newmtl`) has
 // been scanned.
 type MaterialEvent struct {
 
-	// MaterialName holds the name of the material in the declaration
+	// MaterialName holds the name of the material in the declaration.
+	// The name can contain spaces.
 	MaterialName string
 }
 
@@ -53,6 +58,75 @@ type RGBEmissiveColorEvent RGBColorEvent
 // has been scanned.
 type RGBTransmissionFilterEvent RGBColorEvent
 
+// XYZColorEvent indicates that some type of CIE XYZ color declaration
+// has been scanned. You will likely receive a subtype of this structure
+// so you will likely need to do a type-switch.
+type XYZColorEvent struct {
+
+	// Specifies the X component of this color.
+	X float64
+
+	// Specifies the Y component of this color.
+	Y float64
+
+	// Specifies the Z component of this color.
+	Z float64
+}
+
+// XYZAmbientColorEvent indicates that an ambient color declaration
+// (`Ka xyz`) has been scanned.
+type XYZAmbientColorEvent XYZColorEvent
+
+// XYZDiffuseColorEvent indicates that a diffuse color declaration
+// (`Kd xyz`) has been scanned.
+type XYZDiffuseColorEvent XYZColorEvent
+
+// XYZSpecularColorEvent indicates that a specular color declaration
+// (`Ks xyz`) has been scanned.
+type XYZSpecularColorEvent XYZColorEvent
+
+// XYZEmissiveColorEvent indicates that an emissive color declaration
+// (`Ke xyz`) has been scanned.
+type XYZEmissiveColorEvent XYZColorEvent
+
+// XYZTransmissionFilterEvent indicates that a transmission filter
+// declaration (`Tf xyz`) has been scanned.
+type XYZTransmissionFilterEvent XYZColorEvent
+
+// SpectralColorEvent indicates that some type of spectral color
+// declaration has been scanned. You will likely receive a subtype
+// of this structure so you will likely need to do a type-switch.
+type SpectralColorEvent struct {
+
+	// FilePath specifies the location of the spectral curve
+	// file (.rfl) on the filesystem.
+	FilePath string
+
+	// Factor specifies the multiplier for the values in the
+	// spectral curve file. It defaults to 1.0.
+	Factor float64
+}
+
+// SpectralAmbientColorEvent indicates that an ambient color declaration
+// (`Ka spectral`) has been scanned.
+type SpectralAmbientColorEvent SpectralColorEvent
+
+// SpectralDiffuseColorEvent indicates that a diffuse color declaration
+// (`Kd spectral`) has been scanned.
+type SpectralDiffuseColorEvent SpectralColorEvent
+
+// SpectralSpecularColorEvent indicates that a specular color declaration
+// (`Ks spectral`) has been scanned.
+type SpectralSpecularColorEvent SpectralColorEvent
+
+// SpectralEmissiveColorEvent indicates that an emissive color declaration
+// (`Ke spectral`) has been scanned.
+type SpectralEmissiveColorEvent SpectralColorEvent
+
+// SpectralTransmissionFilterEvent indicates that a transmission filter
+// declaration (`Tf spectral`) has been scanned.
+type SpectralTransmissionFilterEvent SpectralColorEvent
+
 // DissolveEvent indicates that a dissolve declaration (`d`) has been
 // scanned.
 type DissolveEvent struct {
@@ -60,6 +134,41 @@ type DissolveEvent struct {
 	// Amount indicates the amount of dissolve, where 1.0 indicates fully
 	// opaque objects and 0.0 fully transparent.
 	Amount float64
+
+	// Halo indicates that the dissolve depends on the surface orientation
+	// relative to the viewer (`d -halo`).
+	Halo bool
+}
+
+// TransparencyEvent indicates that a transparency declaration (`Tr`) has
+// been scanned.
+//
+// This is an alternative to the dissolve declaration, which is used by
+// many exporters, where the amount is inverted.
+type TransparencyEvent struct {
+
+	// Amount indicates the amount of transparency, where 0.0 indicates
+	// fully opaque objects and 1.0 fully transparent.
+	Amount float64
+}
+
+// OpticalDensityEvent indicates that an optical density declaration (`Ni`)
+// has been scanned.
+type OpticalDensityEvent struct {
+
+	// Amount specifies the index of refraction of the surface. The value
+	// ranges between 0.001 and 10.0, where 1.0 means that light does not
+	// bend as it passes through the object.
+	Amount float64
+}
+
+// SharpnessEvent indicates that a sharpness declaration (`sharpness`) has
+// been scanned.
+type SharpnessEvent struct {
+
+	// Amount specifies the sharpness of the reflections from the local
+	// reflection map. The value ranges between 0.0 and 1000.0.
+	Amount float64
 }
 
 // SpecularExponentEvent indicates that a specular exponent declaration (`Ns`)
@@ -92,6 +201,44 @@ type IlluminationEvent struct {
 	Model int64
 }
 
+// PBRFactorEvent indicates that some type of physically based rendering
+// (PBR) factor declaration has been scanned. You will likely receive a
+// subtype of this structure so you will likely need to do a type-switch.
+type PBRFactorEvent struct {
+
+	// Amount specifies the value of the factor. Usually this is in the
+	// range 0.0 to 1.0.
+	Amount float64
+}
+
+// RoughnessEvent indicates that a roughness declaration (`Pr`) has
+// been scanned.
+type RoughnessEvent PBRFactorEvent
+
+// MetallicEvent indicates that a metallic declaration (`Pm`) has
+// been scanned.
+type MetallicEvent PBRFactorEvent
+
+// SheenEvent indicates that a sheen declaration (`Ps`) has been
+// scanned.
+type SheenEvent PBRFactorEvent
+
+// ClearcoatThicknessEvent indicates that a clearcoat thickness
+// declaration (`Pc`) has been scanned.
+type ClearcoatThicknessEvent PBRFactorEvent
+
+// ClearcoatRoughnessEvent indicates that a clearcoat roughness
+// declaration (`Pcr`) has been scanned.
+type ClearcoatRoughnessEvent PBRFactorEvent
+
+// AnisotropyEvent indicates that an anisotropy declaration (`aniso`)
+// has been scanned.
+type AnisotropyEvent PBRFactorEvent
+
+// AnisotropyRotationEvent indicates that an anisotropy rotation
+// declaration (`anisor`) has been scanned.
+type AnisotropyRotationEvent PBRFactorEvent
+
 // TextureEvent indicates that a texture declaration has been scanned.
 // You will likely receive a subtype of this structure so you will likely
 // need to type-switch on this.
@@ -99,6 +246,109 @@ type TextureEvent struct {
 
 	// TexturePath specifies the location of the texture on the filesystem.
 	TexturePath string
+
+	// Options holds the options that were specified before the texture
+	// path. Options that were not specified hold the values returned by
+	// DefaultTextureOptions.
+	Options TextureOptions
+}
+
+// TextureVector holds the three components of a texture option
+// such as an offset or a scale.
+type TextureVector struct {
+
+	// U specifies the horizontal component.
+	U float64
+
+	// V specifies the vertical component.
+	V float64
+
+	// W specifies the depth component.
+	W float64
+}
+
+// TextureOptions holds the options that can be specified as part of
+// a texture declaration.
+type TextureOptions struct {
+
+	// BlendU specifies whether horizontal texture blending is
+	// enabled (`-blendu`).
+	BlendU bool
+
+	// BlendV specifies whether vertical texture blending is
+	// enabled (`-blendv`).
+	BlendV bool
+
+	// BumpMultiplier specifies the amount by which bump values
+	// should be multiplied (`-bm`).
+	BumpMultiplier float64
+
+	// Boost specifies the amount by which the sharpness of
+	// mip-mapped textures should be increased (`-boost`).
+	Boost float64
+
+	// ColorCorrection specifies whether color correction is
+	// enabled (`-cc`).
+	ColorCorrection bool
+
+	// Clamp specifies whether texture coordinates should be
+	// clamped to the range 0.0 to 1.0 (`-clamp`).
+	Clamp bool
+
+	// Channel specifies the channel of the texture that should be
+	// used to create a scalar or bump texture (`-imfchan`). It is
+	// one of `r`, `g`, `b`, `m`, `l`, or `z`, or the empty string
+	// if the option was not specified.
+	Channel string
+
+	// RangeBase specifies the value that should be added to the
+	// texture values (`-mm`).
+	RangeBase float64
+
+	// RangeGain specifies the range over which the texture values
+	// should be spread (`-mm`).
+	RangeGain float64
+
+	// Offset specifies the texture origin offset (`-o`).
+	Offset TextureVector
+
+	// Scale specifies the texture scale (`-s`).
+	Scale TextureVector
+
+	// Turbulence specifies the texture turbulence (`-t`).
+	Turbulence TextureVector
+
+	// Resolution specifies the resolution of the texture that
+	// should be created (`-texres`). It is zero if the option
+	// was not specified.
+	Resolution int64
+
+	// Type specifies the type of a reflection texture (`-type`).
+	// It is one of `sphere`, `cube_top`, `cube_bottom`, `cube_front`,
+	// `cube_back`, `cube_left`, or `cube_right`, or the empty string
+	// if the option was not specified.
+	Type string
+}
+
+// DefaultTextureOptions returns the TextureOptions that apply to a
+// texture declaration that does not specify any options.
+func DefaultTextureOptions() TextureOptions {
+	return TextureOptions{
+		BlendU:          true,
+		BlendV:          true,
+		BumpMultiplier:  1.0,
+		Boost:           0.0,
+		ColorCorrection: false,
+		Clamp:           false,
+		Channel:         "",
+		RangeBase:       0.0,
+		RangeGain:       1.0,
+		Offset:          TextureVector{U: 0.0, V: 0.0, W: 0.0},
+		Scale:           TextureVector{U: 1.0, V: 1.0, W: 1.0},
+		Turbulence:      TextureVector{U: 0.0, V: 0.0, W: 0.0},
+		Resolution:      0,
+		Type:            "",
+	}
 }
 
 // AmbientTextureEvent indicates that an ambient texture declaration (`map_Ka`)
@@ -126,170 +376,410 @@ type SpecularExponentTextureEvent TextureEvent
 type DissolveTextureEvent TextureEvent
 
 // BumpTextureEvent indicates that a bump texture declaration (`map_Bump`)
-// has been scanned.
+// has been scanned. The declaration name is case-insensitive and the
+// `bump` form is accepted as well.
 type BumpTextureEvent TextureEvent
 
+// DisplacementTextureEvent indicates that a displacement texture
+// declaration (`disp`) has been scanned.
+type DisplacementTextureEvent TextureEvent
+
+// DecalTextureEvent indicates that a decal texture declaration (`decal`)
+// has been scanned.
+type DecalTextureEvent TextureEvent
+
+// ReflectionTextureEvent indicates that a reflection texture declaration
+// (`refl`) has been scanned. The Type of the texture options specifies
+// whether this is a spherical reflection map or a face of a cube
+// reflection map.
+type ReflectionTextureEvent TextureEvent
+
+// RoughnessTextureEvent indicates that a roughness texture declaration
+// (`map_Pr`) has been scanned.
+type RoughnessTextureEvent TextureEvent
+
+// MetallicTextureEvent indicates that a metallic texture declaration
+// (`map_Pm`) has been scanned.
+type MetallicTextureEvent TextureEvent
+
+// SheenTextureEvent indicates that a sheen texture declaration
+// (`map_Ps`) has been scanned.
+type SheenTextureEvent TextureEvent
+
+// RMATextureEvent indicates that a combined roughness, metallic, and
+// ambient occlusion texture declaration (`map_RMA`) has been scanned.
+type RMATextureEvent TextureEvent
+
+// NormalTextureEvent indicates that a normal texture declaration
+// (`norm`) has been scanned.
+type NormalTextureEvent TextureEvent
+
 // NewScanner creates a new Scanner object that can scan through
 // Wavefront MTL resources.
+//
+// The scanner uses the options returned by common.DefaultScanOptions.
+// The returned Scanner also implements common.PositionedScanner.
 func NewScanner() common.Scanner {
-	return &scanner{}
+	return NewScannerWithOptions(common.DefaultScanOptions())
+}
+
+// NewScannerWithOptions creates a new Scanner object that can scan
+// through Wavefront MTL resources according to the specified
+// common.ScanOptions.
+//
+// The returned Scanner also implements common.PositionedScanner.
+func NewScannerWithOptions(options common.ScanOptions) common.Scanner {
+	return &scanner{
+		options: &options,
+	}
 }
 
 type scanner struct {
+	options *common.ScanOptions
 }
 
 func (s *scanner) Scan(reader io.Reader, handler common.EventHandler) error {
-	lineScanner := common.NewLineScanner(reader)
+	return s.ScanContext(context.Background(), reader, handler)
+}
+
+func (s *scanner) ScanContext(ctx context.Context, reader io.Reader, handler common.EventHandler) error {
+	return s.ScanPositioned(ctx, reader, func(event common.Event, _ common.EventSource) error {
+		return handler(event)
+	})
+}
+
+func (s *scanner) ScanPositioned(ctx context.Context, reader io.Reader, handler common.PositionedEventHandler) error {
+	err := s.scan(ctx, reader, handler)
+	if errors.Is(err, common.ErrStop) {
+		return nil
+	}
+	return err
+}
+
+func (s *scanner) scan(ctx context.Context, reader io.Reader, handler common.PositionedEventHandler) error {
+	state := &scanState{
+		handler: handler,
+	}
+	tracker := progress.NewTracker(reader, s.options)
+	lineScanner := common.NewLimitedLineScanner(reader, s.options.MaxLineLength)
 
-	for lineScanner.Scan() {
+	interval := max(1, s.options.ContextCheckInterval)
+	for lineCount := 0; lineScanner.Scan(); lineCount++ {
+		if lineCount%interval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
 		line := lineScanner.Line()
+		state.position = line.Position()
+		state.command = ""
+		if line.IsCommand() && !line.IsComment() {
+			state.command = line.CommandName()
+		}
 		switch {
 		case line.IsBlank():
 			// Nothing to do.
 		case line.IsComment():
-			if err := s.processComment(line, handler); err != nil {
+			if err := s.processComment(line, state); err != nil {
 				return err
 			}
 		case line.IsCommand():
-			if err := s.processCommand(line, handler); err != nil {
+			if err := s.processCommand(line, state); err != nil && !s.skipMalformedLine(err) {
 				return err
 			}
 		default:
 			// Ignore line.
 		}
+		tracker.LineScanned(line)
 	}
 
 	if err := lineScanner.Err(); err != nil {
 		return err
 	}
+	tracker.Finish()
 	return nil
 }
 
-func (s *scanner) processComment(line common.Line, handler common.EventHandler) error {
+// scanState holds the state of a single scan, which allows
+// a scanner to be used for multiple scans at the same time.
+type scanState struct {
+	handler  common.PositionedEventHandler
+	position common.Position
+	command  string
+}
+
+// emit passes the specified event, together with the source
+// of the element that triggered it, to the handler.
+func (s *scanState) emit(event common.Event) error {
+	return s.handler(event, common.EventSource{
+		Position: s.position,
+		Command:  s.command,
+	})
+}
+
+// skipMalformedLine reports the specified error as a warning and
+// returns true, if the scanner is lenient and the error was caused
+// by a malformed line.
+func (s *scanner) skipMalformedLine(err error) bool {
+	if s.options.Mode != common.ScanModeLenient {
+		return false
+	}
+	// Handler errors are not wrapped, so only errors that were
+	// produced by the scanner itself are matched here.
+	parseErr, ok := err.(*common.ParseError)
+	if !ok {
+		return false
+	}
+	if s.options.WarningHandler != nil {
+		s.options.WarningHandler(common.Warning(*parseErr))
+	}
+	return true
+}
+
+func (s *scanner) processComment(line common.Line, state *scanState) error {
 	event := common.CommentEvent{
 		Comment: line.Comment(),
 	}
-	return handler(event)
+	return state.emit(event)
 }
 
-func (s *scanner) processCommand(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processUnknownCommand(line common.Line, state *scanState) error {
+	// Comment directives are comments as far as the specification
+	// is concerned, so they are not rejected in strict mode.
+	if s.options.Mode == common.ScanModeStrict && !line.IsCommentDirective() {
+		return common.NewParseError(line, fmt.Errorf("%w: unknown command", common.ErrInvalid))
+	}
+	event := common.UnknownCommandEvent{
+		CommandName: line.CommandName(),
+		Params:      line.Params(),
+	}
+	return state.emit(event)
+}
+
+func (s *scanner) processCommand(line common.Line, state *scanState) error {
 	switch {
 	case line.HasCommandName("newmtl"):
-		return s.processMaterial(line, handler)
+		return s.processMaterial(line, state)
 	case line.HasCommandName("Ka"):
-		return s.processAmbientColor(line, handler)
+		return s.processAmbientColor(line, state)
 	case line.HasCommandName("Kd"):
-		return s.processDiffuseColor(line, handler)
+		return s.processDiffuseColor(line, state)
 	case line.HasCommandName("Ks"):
-		return s.processSpecularColor(line, handler)
+		return s.processSpecularColor(line, state)
 	case line.HasCommandName("Ke"):
-		return s.processEmissiveColor(line, handler)
+		return s.processEmissiveColor(line, state)
 	case line.HasCommandName("Tf"):
-		return s.processTransmissionFilter(line, handler)
+		return s.processTransmissionFilter(line, state)
 	case line.HasCommandName("d"):
-		return s.processDissolve(line, handler)
+		return s.processDissolve(line, state)
+	case line.HasCommandName("Tr"):
+		return s.processTransparency(line, state)
 	case line.HasCommandName("Ns"):
-		return s.processSpecularExponent(line, handler)
+		return s.processSpecularExponent(line, state)
+	case line.HasCommandName("Ni"):
+		return s.processOpticalDensity(line, state)
+	case line.HasCommandName("sharpness"):
+		return s.processSharpness(line, state)
 	case line.HasCommandName("illum"):
-		return s.processIlluminationModel(line, handler)
+		return s.processIlluminationModel(line, state)
 	case line.HasCommandName("map_Ka"):
-		return s.processAmbientTexture(line, handler)
+		return s.processAmbientTexture(line, state)
 	case line.HasCommandName("map_Kd"):
-		return s.processDiffuseTexture(line, handler)
+		return s.processDiffuseTexture(line, state)
 	case line.HasCommandName("map_Ks"):
-		return s.processSpecularTexture(line, handler)
+		return s.processSpecularTexture(line, state)
 	case line.HasCommandName("map_Ke"):
-		return s.processEmissiveTexture(line, handler)
+		return s.processEmissiveTexture(line, state)
 	case line.HasCommandName("map_Ns"):
-		return s.processSpecularExponentTexture(line, handler)
+		return s.processSpecularExponentTexture(line, state)
 	case line.HasCommandName("map_d"):
-		return s.processDissolveTexture(line, handler)
-	case line.HasCommandName("map_Bump"):
-		return s.processBumpTexture(line, handler)
+		return s.processDissolveTexture(line, state)
+	case s.hasCommandNameFold(line, "map_Bump", "bump"):
+		return s.processBumpTexture(line, state)
+	case s.hasCommandNameFold(line, "disp", "map_disp"):
+		return s.processDisplacementTexture(line, state)
+	case s.hasCommandNameFold(line, "decal", "map_decal"):
+		return s.processDecalTexture(line, state)
+	case s.hasCommandNameFold(line, "refl", "map_refl"):
+		return s.processReflectionTexture(line, state)
+	case line.HasCommandName("Pr"):
+		return s.processRoughness(line, state)
+	case line.HasCommandName("Pm"):
+		return s.processMetallic(line, state)
+	case line.HasCommandName("Ps"):
+		return s.processSheen(line, state)
+	case line.HasCommandName("Pc"):
+		return s.processClearcoatThickness(line, state)
+	case line.HasCommandName("Pcr"):
+		return s.processClearcoatRoughness(line, state)
+	case line.HasCommandName("aniso"):
+		return s.processAnisotropy(line, state)
+	case line.HasCommandName("anisor"):
+		return s.processAnisotropyRotation(line, state)
+	case line.HasCommandName("map_Pr"):
+		return s.processRoughnessTexture(line, state)
+	case line.HasCommandName("map_Pm"):
+		return s.processMetallicTexture(line, state)
+	case line.HasCommandName("map_Ps"):
+		return s.processSheenTexture(line, state)
+	case line.HasCommandName("map_RMA"):
+		return s.processRMATexture(line, state)
+	case line.HasCommandName("norm"):
+		return s.processNormalTexture(line, state)
 	default:
-		return nil
+		return s.processUnknownCommand(line, state)
+	}
+}
+
+// hasCommandNameFold checks whether the command of the line has one
+// of the specified names, ignoring case. Exporters are inconsistent
+// in the spelling of some of the declarations.
+func (s *scanner) hasCommandNameFold(line common.Line, names ...string) bool {
+	for _, name := range names {
+		if strings.EqualFold(line.CommandName(), name) {
+			return true
+		}
 	}
+	return false
 }
 
-func (s *scanner) processMaterial(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processMaterial(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: material declaration lacks name", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: material declaration lacks name", common.ErrInvalid))
 	}
-	name := line.StringParam(0)
+	// The name is the only argument and can contain spaces.
 	event := MaterialEvent{
-		MaterialName: name,
+		MaterialName: line.Remainder(0),
 	}
-	return handler(event)
+	return state.emit(event)
 }
 
-func (s *scanner) processAmbientColor(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processAmbientColor(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: ambient color declaration lacks parameters", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: ambient color declaration lacks parameters", common.ErrInvalid))
 	}
-	if s.isSpectralColor(line) || s.isXYZColor(line) {
-		return nil
+	switch {
+	case s.isSpectralColor(line):
+		event, err := s.getSpectralColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(SpectralAmbientColorEvent(event))
+	case s.isXYZColor(line):
+		event, err := s.getXYZColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(XYZAmbientColorEvent(event))
 	}
 	event, err := s.getRGBColorEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(RGBAmbientColorEvent(event))
+	return state.emit(RGBAmbientColorEvent(event))
 }
 
-func (s *scanner) processDiffuseColor(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processDiffuseColor(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: diffuse color declaration lacks parameters", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: diffuse color declaration lacks parameters", common.ErrInvalid))
 	}
-	if s.isSpectralColor(line) || s.isXYZColor(line) {
-		return nil
+	switch {
+	case s.isSpectralColor(line):
+		event, err := s.getSpectralColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(SpectralDiffuseColorEvent(event))
+	case s.isXYZColor(line):
+		event, err := s.getXYZColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(XYZDiffuseColorEvent(event))
 	}
 	event, err := s.getRGBColorEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(RGBDiffuseColorEvent(event))
+	return state.emit(RGBDiffuseColorEvent(event))
 }
 
-func (s *scanner) processSpecularColor(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processSpecularColor(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: specular color declaration lacks parameters", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: specular color declaration lacks parameters", common.ErrInvalid))
 	}
-	if s.isSpectralColor(line) || s.isXYZColor(line) {
-		return nil
+	switch {
+	case s.isSpectralColor(line):
+		event, err := s.getSpectralColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(SpectralSpecularColorEvent(event))
+	case s.isXYZColor(line):
+		event, err := s.getXYZColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(XYZSpecularColorEvent(event))
 	}
 	event, err := s.getRGBColorEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(RGBSpecularColorEvent(event))
+	return state.emit(RGBSpecularColorEvent(event))
 }
 
-func (s *scanner) processEmissiveColor(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processEmissiveColor(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: emissive color declaration lacks parameters", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: emissive color declaration lacks parameters", common.ErrInvalid))
 	}
-	if s.isSpectralColor(line) || s.isXYZColor(line) {
-		return nil
+	switch {
+	case s.isSpectralColor(line):
+		event, err := s.getSpectralColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(SpectralEmissiveColorEvent(event))
+	case s.isXYZColor(line):
+		event, err := s.getXYZColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(XYZEmissiveColorEvent(event))
 	}
 	event, err := s.getRGBColorEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(RGBEmissiveColorEvent(event))
+	return state.emit(RGBEmissiveColorEvent(event))
 }
 
-func (s *scanner) processTransmissionFilter(line common.Line, handler common.EventHandler) error {
-	if line.ParamCount() < 3 {
-		return fmt.Errorf("%w: transmission filter declaration lacks parameters", common.ErrInvalid)
+func (s *scanner) processTransmissionFilter(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: transmission filter declaration lacks parameters", common.ErrInvalid))
 	}
-	if s.isSpectralColor(line) || s.isXYZColor(line) {
-		return nil
+	switch {
+	case s.isSpectralColor(line):
+		event, err := s.getSpectralColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(SpectralTransmissionFilterEvent(event))
+	case s.isXYZColor(line):
+		event, err := s.getXYZColorEvent(line)
+		if err != nil {
+			return err
+		}
+		return state.emit(XYZTransmissionFilterEvent(event))
+	}
+	if line.ParamCount() < 3 {
+		return common.NewParseError(line, fmt.Errorf("%w: transmission filter declaration lacks parameters", common.ErrInvalid))
 	}
 	event, err := s.getRGBColorEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(RGBTransmissionFilterEvent(event))
+	return state.emit(RGBTransmissionFilterEvent(event))
 }
 
 func (s *scanner) isSpectralColor(line common.Line) bool {
@@ -300,156 +790,657 @@ func (s *scanner) isXYZColor(line common.Line) bool {
 	return line.StringParam(0) == "xyz"
 }
 
+func (s *scanner) getXYZColorEvent(line common.Line) (XYZColorEvent, error) {
+	if line.ParamCount() < 2 {
+		return XYZColorEvent{}, common.NewParseError(line, fmt.Errorf("%w: xyz color declaration lacks parameters", common.ErrInvalid))
+	}
+
+	var err error
+	var event XYZColorEvent
+
+	event.X, err = line.FloatParam(1)
+	if err != nil {
+		return XYZColorEvent{}, common.NewParamParseError(line, 1, err)
+	}
+
+	if line.ParamCount() >= 4 {
+		event.Y, err = line.FloatParam(2)
+		if err != nil {
+			return XYZColorEvent{}, common.NewParamParseError(line, 2, err)
+		}
+
+		event.Z, err = line.FloatParam(3)
+		if err != nil {
+			return XYZColorEvent{}, common.NewParamParseError(line, 3, err)
+		}
+	} else {
+		event.Y = event.X
+		event.Z = event.X
+	}
+
+	if err := s.checkParamCount(line, 4); err != nil {
+		return XYZColorEvent{}, err
+	}
+	return event, nil
+}
+
+func (s *scanner) getSpectralColorEvent(line common.Line) (SpectralColorEvent, error) {
+	if line.ParamCount() < 2 {
+		return SpectralColorEvent{}, common.NewParseError(line, fmt.Errorf("%w: spectral color declaration lacks filename parameter", common.ErrInvalid))
+	}
+
+	event := SpectralColorEvent{
+		FilePath: line.StringParam(1),
+		Factor:   1.0,
+	}
+
+	if line.ParamCount() >= 3 {
+		var err error
+		event.Factor, err = line.FloatParam(2)
+		if err != nil {
+			return SpectralColorEvent{}, common.NewParamParseError(line, 2, err)
+		}
+	}
+
+	if err := s.checkParamCount(line, 3); err != nil {
+		return SpectralColorEvent{}, err
+	}
+	return event, nil
+}
+
 func (s *scanner) getRGBColorEvent(line common.Line) (RGBColorEvent, error) {
 	var err error
 	var event RGBColorEvent
 
 	event.R, err = line.FloatParam(0)
 	if err != nil {
-		return RGBColorEvent{}, err
+		return RGBColorEvent{}, common.NewParamParseError(line, 0, err)
 	}
 
 	if line.ParamCount() >= 3 {
 		event.G, err = line.FloatParam(1)
 		if err != nil {
-			return RGBColorEvent{}, err
+			return RGBColorEvent{}, common.NewParamParseError(line, 1, err)
 		}
 
 		event.B, err = line.FloatParam(2)
 		if err != nil {
-			return RGBColorEvent{}, err
+			return RGBColorEvent{}, common.NewParamParseError(line, 2, err)
 		}
 	} else {
 		event.G = event.R
 		event.B = event.R
 	}
 
+	if err := s.checkParamCount(line, 3); err != nil {
+		return RGBColorEvent{}, err
+	}
 	return event, nil
 }
 
-func (s *scanner) processDissolve(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processDissolve(line common.Line, state *scanState) error {
+	event := DissolveEvent{}
+	index := 0
+	if line.ParamCount() > 0 && line.StringParam(0) == "-halo" {
+		event.Halo = true
+		index++
+	}
+	if line.ParamCount() <= index {
+		return common.NewParseError(line, fmt.Errorf("%w: dissolve declaration lacks value parameter", common.ErrInvalid))
+	}
+	amount, err := line.FloatParam(index)
+	if err != nil {
+		return common.NewParamParseError(line, index, err)
+	}
+	if err := s.checkParamCount(line, index+1); err != nil {
+		return err
+	}
+	if err := s.checkParamRange(line, index, amount, 0.0, 1.0); err != nil {
+		return err
+	}
+	event.Amount = amount
+	return state.emit(event)
+}
+
+func (s *scanner) processTransparency(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: dissolve declaration lacks value parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: transparency declaration lacks value parameter", common.ErrInvalid))
 	}
 	amount, err := line.FloatParam(0)
 	if err != nil {
+		return common.NewParamParseError(line, 0, err)
+	}
+	if err := s.checkParamCount(line, 1); err != nil {
+		return err
+	}
+	if err := s.checkParamRange(line, 0, amount, 0.0, 1.0); err != nil {
 		return err
 	}
-	event := DissolveEvent{
+	event := TransparencyEvent{
 		Amount: amount,
 	}
-	return handler(event)
+	return state.emit(event)
 }
 
-func (s *scanner) processSpecularExponent(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processSpecularExponent(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: specular exponent declaration lacks value parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: specular exponent declaration lacks value parameter", common.ErrInvalid))
 	}
 	amount, err := line.FloatParam(0)
 	if err != nil {
+		return common.NewParamParseError(line, 0, err)
+	}
+	if err := s.checkParamCount(line, 1); err != nil {
+		return err
+	}
+	if err := s.checkParamRange(line, 0, amount, 0.0, 1000.0); err != nil {
 		return err
 	}
 	event := SpecularExponentEvent{
 		Amount: amount,
 	}
-	return handler(event)
+	return state.emit(event)
 }
 
-func (s *scanner) processIlluminationModel(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processOpticalDensity(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: illumination model declaration lacks value parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: optical density declaration lacks value parameter", common.ErrInvalid))
+	}
+	amount, err := line.FloatParam(0)
+	if err != nil {
+		return common.NewParamParseError(line, 0, err)
+	}
+	if err := s.checkParamCount(line, 1); err != nil {
+		return err
+	}
+	if err := s.checkParamRange(line, 0, amount, 0.001, 10.0); err != nil {
+		return err
+	}
+	event := OpticalDensityEvent{
+		Amount: amount,
+	}
+	return state.emit(event)
+}
+
+func (s *scanner) processSharpness(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: sharpness declaration lacks value parameter", common.ErrInvalid))
+	}
+	amount, err := line.FloatParam(0)
+	if err != nil {
+		return common.NewParamParseError(line, 0, err)
+	}
+	if err := s.checkParamCount(line, 1); err != nil {
+		return err
+	}
+	if err := s.checkParamRange(line, 0, amount, 0.0, 1000.0); err != nil {
+		return err
+	}
+	event := SharpnessEvent{
+		Amount: amount,
+	}
+	return state.emit(event)
+}
+
+func (s *scanner) processIlluminationModel(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: illumination model declaration lacks value parameter", common.ErrInvalid))
 	}
 	model, err := line.IntParam(0)
 	if err != nil {
+		return common.NewParamParseError(line, 0, err)
+	}
+	if err := s.checkParamCount(line, 1); err != nil {
+		return err
+	}
+	if err := s.checkParamRange(line, 0, float64(model), 0, 10); err != nil {
 		return err
 	}
 	event := IlluminationEvent{
 		Model: model,
 	}
-	return handler(event)
+	return state.emit(event)
+}
+
+func (s *scanner) processAmbientTexture(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: ambient texture declaration lacks filename parameter", common.ErrInvalid))
+	}
+	event, err := s.getTextureEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(AmbientTextureEvent(event))
+}
+
+func (s *scanner) processDiffuseTexture(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: diffuse texture declaration lacks filename parameter", common.ErrInvalid))
+	}
+	event, err := s.getTextureEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(DiffuseTextureEvent(event))
 }
 
-func (s *scanner) processAmbientTexture(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processSpecularTexture(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: ambient texture declaration lacks filename parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: specular texture declaration lacks filename parameter", common.ErrInvalid))
 	}
 	event, err := s.getTextureEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(AmbientTextureEvent(event))
+	return state.emit(SpecularTextureEvent(event))
 }
 
-func (s *scanner) processDiffuseTexture(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processEmissiveTexture(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: diffuse texture declaration lacks filename parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: emissive texture declaration lacks filename parameter", common.ErrInvalid))
 	}
 	event, err := s.getTextureEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(DiffuseTextureEvent(event))
+	return state.emit(EmissiveTextureEvent(event))
+}
+
+func (s *scanner) processSpecularExponentTexture(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: specular exponent texture declaration lacks filename parameter", common.ErrInvalid))
+	}
+	event, err := s.getTextureEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(SpecularExponentTextureEvent(event))
+}
+
+func (s *scanner) processDissolveTexture(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: dissolve texture declaration lacks filename parameter", common.ErrInvalid))
+	}
+	event, err := s.getTextureEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(DissolveTextureEvent(event))
+}
+
+func (s *scanner) processBumpTexture(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: bump texture declaration lacks filename parameter", common.ErrInvalid))
+	}
+	event, err := s.getTextureEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(BumpTextureEvent(event))
+}
+
+func (s *scanner) processDisplacementTexture(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: displacement texture declaration lacks filename parameter", common.ErrInvalid))
+	}
+	event, err := s.getTextureEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(DisplacementTextureEvent(event))
+}
+
+func (s *scanner) processDecalTexture(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: decal texture declaration lacks filename parameter", common.ErrInvalid))
+	}
+	event, err := s.getTextureEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(DecalTextureEvent(event))
+}
+
+func (s *scanner) processReflectionTexture(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: reflection texture declaration lacks filename parameter", common.ErrInvalid))
+	}
+	event, err := s.getTextureEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(ReflectionTextureEvent(event))
+}
+
+func (s *scanner) processRoughness(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: roughness declaration lacks value parameter", common.ErrInvalid))
+	}
+	event, err := s.getPBRFactorEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(RoughnessEvent(event))
+}
+
+func (s *scanner) processMetallic(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: metallic declaration lacks value parameter", common.ErrInvalid))
+	}
+	event, err := s.getPBRFactorEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(MetallicEvent(event))
+}
+
+func (s *scanner) processSheen(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: sheen declaration lacks value parameter", common.ErrInvalid))
+	}
+	event, err := s.getPBRFactorEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(SheenEvent(event))
+}
+
+func (s *scanner) processClearcoatThickness(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: clearcoat thickness declaration lacks value parameter", common.ErrInvalid))
+	}
+	event, err := s.getPBRFactorEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(ClearcoatThicknessEvent(event))
+}
+
+func (s *scanner) processClearcoatRoughness(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: clearcoat roughness declaration lacks value parameter", common.ErrInvalid))
+	}
+	event, err := s.getPBRFactorEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(ClearcoatRoughnessEvent(event))
+}
+
+func (s *scanner) processAnisotropy(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: anisotropy declaration lacks value parameter", common.ErrInvalid))
+	}
+	event, err := s.getPBRFactorEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(AnisotropyEvent(event))
+}
+
+func (s *scanner) processAnisotropyRotation(line common.Line, state *scanState) error {
+	if line.ParamCount() < 1 {
+		return common.NewParseError(line, fmt.Errorf("%w: anisotropy rotation declaration lacks value parameter", common.ErrInvalid))
+	}
+	event, err := s.getPBRFactorEvent(line)
+	if err != nil {
+		return err
+	}
+	return state.emit(AnisotropyRotationEvent(event))
+}
+
+func (s *scanner) getPBRFactorEvent(line common.Line) (PBRFactorEvent, error) {
+	amount, err := line.FloatParam(0)
+	if err != nil {
+		return PBRFactorEvent{}, common.NewParamParseError(line, 0, err)
+	}
+	if err := s.checkParamCount(line, 1); err != nil {
+		return PBRFactorEvent{}, err
+	}
+	if err := s.checkParamRange(line, 0, amount, 0.0, 1.0); err != nil {
+		return PBRFactorEvent{}, err
+	}
+	event := PBRFactorEvent{
+		Amount: amount,
+	}
+	return event, nil
 }
 
-func (s *scanner) processSpecularTexture(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processRoughnessTexture(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: specular texture declaration lacks filename parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: roughness texture declaration lacks filename parameter", common.ErrInvalid))
 	}
 	event, err := s.getTextureEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(SpecularTextureEvent(event))
+	return state.emit(RoughnessTextureEvent(event))
 }
 
-func (s *scanner) processEmissiveTexture(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processMetallicTexture(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: emissive texture declaration lacks filename parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: metallic texture declaration lacks filename parameter", common.ErrInvalid))
 	}
 	event, err := s.getTextureEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(EmissiveTextureEvent(event))
+	return state.emit(MetallicTextureEvent(event))
 }
 
-func (s *scanner) processSpecularExponentTexture(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processSheenTexture(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: specular exponent texture declaration lacks filename parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: sheen texture declaration lacks filename parameter", common.ErrInvalid))
 	}
 	event, err := s.getTextureEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(SpecularExponentTextureEvent(event))
+	return state.emit(SheenTextureEvent(event))
 }
 
-func (s *scanner) processDissolveTexture(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processRMATexture(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: dissolve texture declaration lacks filename parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: RMA texture declaration lacks filename parameter", common.ErrInvalid))
 	}
 	event, err := s.getTextureEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(DissolveTextureEvent(event))
+	return state.emit(RMATextureEvent(event))
 }
 
-func (s *scanner) processBumpTexture(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processNormalTexture(line common.Line, state *scanState) error {
 	if line.ParamCount() < 1 {
-		return fmt.Errorf("%w: bump texture declaration lacks filename parameter", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: normal texture declaration lacks filename parameter", common.ErrInvalid))
 	}
 	event, err := s.getTextureEvent(line)
 	if err != nil {
 		return err
 	}
-	return handler(BumpTextureEvent(event))
+	return state.emit(NormalTextureEvent(event))
+}
+
+// checkParamCount returns an error if the scanner is strict and
+// the line has more than the specified number of parameters.
+func (s *scanner) checkParamCount(line common.Line, maxCount int) error {
+	if s.options.Mode != common.ScanModeStrict || line.ParamCount() <= maxCount {
+		return nil
+	}
+	return common.NewParamParseError(line, maxCount, fmt.Errorf("%w: unexpected parameter", common.ErrInvalid))
+}
+
+// checkParamRange returns an error if the scanner is strict and
+// the value of the parameter at the specified index is outside
+// of the specified range.
+func (s *scanner) checkParamRange(line common.Line, index int, value, minValue, maxValue float64) error {
+	if s.options.Mode != common.ScanModeStrict || (value >= minValue && value <= maxValue) {
+		return nil
+	}
+	return common.NewParamParseError(line, index, fmt.Errorf("%w: value %v is outside of the range [%v, %v]", common.ErrInvalid, value, minValue, maxValue))
 }
 
 func (s *scanner) getTextureEvent(line common.Line) (TextureEvent, error) {
-	path := line.StringParam(0)
 	event := TextureEvent{
-		TexturePath: path,
+		Options: DefaultTextureOptions(),
+	}
+	index := 0
+	for index < line.ParamCount() && strings.HasPrefix(line.StringParam(index), "-") {
+		next, err := s.processTextureOption(line, index, &event.Options)
+		if err != nil {
+			return TextureEvent{}, err
+		}
+		index = next
+	}
+	if index >= line.ParamCount() {
+		return TextureEvent{}, common.NewParseError(line, fmt.Errorf("%w: texture declaration lacks filename parameter", common.ErrInvalid))
 	}
+	// The path is the last argument and can contain spaces.
+	event.TexturePath = line.Remainder(index)
 	return event, nil
 }
+
+// processTextureOption parses the texture option at the specified index
+// and returns the index of the parameter that follows it.
+func (s *scanner) processTextureOption(line common.Line, index int, options *TextureOptions) (int, error) {
+	var err error
+	next := index + 1
+	switch name := line.StringParam(index); name {
+	case "-blendu":
+		options.BlendU, err = s.getTextureSwitch(line, next)
+		next++
+	case "-blendv":
+		options.BlendV, err = s.getTextureSwitch(line, next)
+		next++
+	case "-cc":
+		options.ColorCorrection, err = s.getTextureSwitch(line, next)
+		next++
+	case "-clamp":
+		options.Clamp, err = s.getTextureSwitch(line, next)
+		next++
+	case "-bm":
+		options.BumpMultiplier, err = s.getTextureFloat(line, next)
+		next++
+	case "-boost":
+		options.Boost, err = s.getTextureFloat(line, next)
+		next++
+	case "-mm":
+		options.RangeBase, err = s.getTextureFloat(line, next)
+		if err == nil {
+			options.RangeGain, err = s.getTextureFloat(line, next+1)
+		}
+		next += 2
+	case "-imfchan":
+		options.Channel, err = s.getTextureChannel(line, next)
+		next++
+	case "-texres":
+		options.Resolution, err = s.getTextureResolution(line, next)
+		next++
+	case "-o":
+		next, err = s.getTextureVector(line, next, &options.Offset)
+	case "-s":
+		next, err = s.getTextureVector(line, next, &options.Scale)
+	case "-t":
+		next, err = s.getTextureVector(line, next, &options.Turbulence)
+	case "-type":
+		options.Type, err = s.getTextureType(line, next)
+		next++
+	default:
+		return 0, common.NewParamParseError(line, index, fmt.Errorf("%w: unknown texture option %q", common.ErrInvalid, name))
+	}
+	if err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+func (s *scanner) getTextureSwitch(line common.Line, index int) (bool, error) {
+	if index >= line.ParamCount() {
+		return false, s.newMissingTextureOptionValueError(line)
+	}
+	switch line.StringParam(index) {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	default:
+		return false, common.NewParamParseError(line, index, fmt.Errorf("%w: texture option value should be on or off", common.ErrInvalid))
+	}
+}
+
+func (s *scanner) getTextureFloat(line common.Line, index int) (float64, error) {
+	if index >= line.ParamCount() {
+		return 0.0, s.newMissingTextureOptionValueError(line)
+	}
+	value, err := line.FloatParam(index)
+	if err != nil {
+		return 0.0, common.NewParamParseError(line, index, err)
+	}
+	return value, nil
+}
+
+func (s *scanner) getTextureChannel(line common.Line, index int) (string, error) {
+	if index >= line.ParamCount() {
+		return "", s.newMissingTextureOptionValueError(line)
+	}
+	switch channel := line.StringParam(index); channel {
+	case "r", "g", "b", "m", "l", "z":
+		return channel, nil
+	default:
+		return "", common.NewParamParseError(line, index, fmt.Errorf("%w: unknown texture channel %q", common.ErrInvalid, channel))
+	}
+}
+
+func (s *scanner) getTextureType(line common.Line, index int) (string, error) {
+	if index >= line.ParamCount() {
+		return "", s.newMissingTextureOptionValueError(line)
+	}
+	switch textureType := line.StringParam(index); textureType {
+	case "sphere", "cube_top", "cube_bottom", "cube_front", "cube_back", "cube_left", "cube_right":
+		return textureType, nil
+	default:
+		return "", common.NewParamParseError(line, index, fmt.Errorf("%w: unknown texture type %q", common.ErrInvalid, textureType))
+	}
+}
+
+func (s *scanner) getTextureResolution(line common.Line, index int) (int64, error) {
+	if index >= line.ParamCount() {
+		return 0, s.newMissingTextureOptionValueError(line)
+	}
+	value, err := line.IntParam(index)
+	if err != nil {
+		return 0, common.NewParamParseError(line, index, err)
+	}
+	return value, nil
+}
+
+// getTextureVector parses a vector option, where the V and W components
+// are optional and keep their current values if not specified. It returns
+// the index of the parameter that follows the vector.
+func (s *scanner) getTextureVector(line common.Line, index int, vector *TextureVector) (int, error) {
+	var err error
+	vector.U, err = s.getTextureFloat(line, index)
+	if err != nil {
+		return 0, err
+	}
+	components := []*float64{&vector.V, &vector.W}
+	next := index + 1
+	for _, component := range components {
+		// The last parameter is always part of the texture path,
+		// even if it happens to look like a number.
+		if next >= line.ParamCount()-1 {
+			break
+		}
+		value, err := line.FloatParam(next)
+		if err != nil {
+			break
+		}
+		*component = value
+		next++
+	}
+	return next, nil
+}
+
+func (s *scanner) newMissingTextureOptionValueError(line common.Line) error {
+	return common.NewParseError(line, fmt.Errorf("%w: texture option lacks value parameter", common.ErrInvalid))
+}
@@ -1,6 +1,7 @@
 package mtl_test
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -18,6 +19,7 @@ var _ = Describe("Scanner", func() {
 
 	var (
 		testFile       string
+		scanner        common.Scanner
 		handler        common.EventHandler
 		trackedHandler *testutil.EventHandlerTracker
 		eventCounter   int
@@ -39,6 +41,17 @@ var _ = Describe("Scanner", func() {
 		})
 	}
 
+	itShouldHaveReturnedAParseError := func(expected common.ParseError) {
+		GinkgoHelper()
+		It("should have returned a parse error", func() {
+			var parseErr *common.ParseError
+			Expect(errors.As(scanErr, &parseErr)).To(BeTrue())
+			Expect(parseErr.Line).To(Equal(expected.Line))
+			Expect(parseErr.Column).To(Equal(expected.Column))
+			Expect(parseErr.Command).To(Equal(expected.Command))
+		})
+	}
+
 	itShouldHaveReturnedHandlerError := func() {
 		GinkgoHelper()
 		It("should have returned handler error", func() {
@@ -63,11 +76,11 @@ var _ = Describe("Scanner", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer file.Close()
 
-		scanner := mtl.NewScanner()
 		scanErr = scanner.Scan(file, handler)
 	})
 
 	BeforeEach(func() {
+		scanner = mtl.NewScanner()
 		trackedHandler = new(testutil.EventHandlerTracker)
 		eventCounter = 0
 
@@ -78,6 +91,37 @@ var _ = Describe("Scanner", func() {
 		handler = trackedHandler.Handle
 	})
 
+	When("the positions of events are tracked", func() {
+		var sources []common.EventSource
+
+		BeforeEach(func() {
+			testFile = "valid_basic.mtl"
+		})
+
+		JustBeforeEach(func() {
+			file, err := os.Open(filepath.Join("testdata", testFile))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			sources = nil
+			scanErr = scanner.(common.PositionedScanner).ScanPositioned(context.Background(), file, func(event common.Event, source common.EventSource) error {
+				sources = append(sources, source)
+				return nil
+			})
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have provided the source of each event", func() {
+			Expect(len(sources)).To(BeNumerically(">=", 3))
+			Expect(sources[:3]).To(Equal([]common.EventSource{
+				{Position: common.Position{Line: 1, Column: 1}, Command: ""},       // comment
+				{Position: common.Position{Line: 3, Column: 1}, Command: "newmtl"}, // material
+				{Position: common.Position{Line: 5, Column: 1}, Command: "Ka"},     // ambient color
+			}))
+		})
+	})
+
 	Describe("basic MTL file", func() {
 		BeforeEach(func() {
 			testFile = "valid_basic.mtl"
@@ -128,24 +172,31 @@ var _ = Describe("Scanner", func() {
 			})
 			assertEvent(mtl.AmbientTextureEvent{
 				TexturePath: "textures/ambient.bmp",
+				Options:     mtl.DefaultTextureOptions(),
 			})
 			assertEvent(mtl.DiffuseTextureEvent{
 				TexturePath: "textures/diffuse.bmp",
+				Options:     mtl.DefaultTextureOptions(),
 			})
 			assertEvent(mtl.SpecularTextureEvent{
 				TexturePath: "textures/specular.bmp",
+				Options:     mtl.DefaultTextureOptions(),
 			})
 			assertEvent(mtl.SpecularExponentTextureEvent{
 				TexturePath: "textures/specular_exponent.bmp",
+				Options:     mtl.DefaultTextureOptions(),
 			})
 			assertEvent(mtl.DissolveTextureEvent{
 				TexturePath: "textures/dissolve.bmp",
+				Options:     mtl.DefaultTextureOptions(),
 			})
 			assertEvent(mtl.EmissiveTextureEvent{
 				TexturePath: "textures/emissive.png",
+				Options:     mtl.DefaultTextureOptions(),
 			})
 			assertEvent(mtl.BumpTextureEvent{
 				TexturePath: "textures/bump.png",
+				Options:     mtl.DefaultTextureOptions(),
 			})
 			assertNoMoreEvents()
 		})
@@ -281,24 +332,377 @@ var _ = Describe("Scanner", func() {
 		})
 	})
 
-	When("reading unsupported declarations", func() {
+	When("reading textures with options", func() {
+		BeforeEach(func() {
+			testFile = "valid_texture_options.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned all the options", func() {
+			diffuseOptions := mtl.DefaultTextureOptions()
+			diffuseOptions.Scale = mtl.TextureVector{U: 2.0, V: 2.0, W: 1.0}
+			diffuseOptions.Clamp = true
+			assertEvent(mtl.DiffuseTextureEvent{
+				TexturePath: "wood.png",
+				Options:     diffuseOptions,
+			})
+
+			ambientOptions := mtl.DefaultTextureOptions()
+			ambientOptions.BlendU = false
+			ambientOptions.BlendV = false
+			ambientOptions.ColorCorrection = true
+			ambientOptions.Boost = 1.5
+			ambientOptions.RangeBase = 0.1
+			ambientOptions.RangeGain = 0.8
+			ambientOptions.Resolution = 512
+			assertEvent(mtl.AmbientTextureEvent{
+				TexturePath: "ambient.png",
+				Options:     ambientOptions,
+			})
+
+			bumpOptions := mtl.DefaultTextureOptions()
+			bumpOptions.BumpMultiplier = 0.5
+			bumpOptions.Channel = "l"
+			bumpOptions.Offset = mtl.TextureVector{U: 0.5, V: 0.0, W: 0.0}
+			bumpOptions.Turbulence = mtl.TextureVector{U: 0.1, V: 0.2, W: 0.0}
+			assertEvent(mtl.BumpTextureEvent{
+				TexturePath: "bump.png",
+				Options:     bumpOptions,
+			})
+
+			specularOptions := mtl.DefaultTextureOptions()
+			specularOptions.Scale = mtl.TextureVector{U: 2.0, V: 1.0, W: 1.0}
+			assertEvent(mtl.SpecularTextureEvent{
+				TexturePath: "3.png",
+				Options:     specularOptions,
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("reading textures with spaces in their paths", func() {
+		BeforeEach(func() {
+			testFile = "valid_texture_path_spaces.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned the full paths", func() {
+			assertEvent(mtl.DiffuseTextureEvent{
+				TexturePath: "My Textures/brick wall.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+
+			ambientOptions := mtl.DefaultTextureOptions()
+			ambientOptions.Clamp = true
+			ambientOptions.Scale = mtl.TextureVector{U: 2.0, V: 1.0, W: 1.0}
+			assertEvent(mtl.AmbientTextureEvent{
+				TexturePath: "ambient occlusion.png",
+				Options:     ambientOptions,
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("reading materials with spaces in their names", func() {
+		BeforeEach(func() {
+			testFile = "valid_material_name_spaces.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned the full names", func() {
+			assertEvent(mtl.MaterialEvent{
+				MaterialName: "My  Material",
+			})
+			assertEvent(mtl.MaterialEvent{
+				MaterialName: "Other",
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("reading texture option with invalid value", func() {
+		BeforeEach(func() {
+			testFile = "error_invalid_texture_option_value.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 15, Command: "map_Kd",
+		})
+	})
+
+	When("reading unknown texture option", func() {
+		BeforeEach(func() {
+			testFile = "error_unknown_texture_option.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 8, Command: "map_Kd",
+		})
+	})
+
+	When("reading texture options without filename param", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_texture_option_filename.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("reading displacement, decal, and reflection textures", func() {
+		BeforeEach(func() {
+			testFile = "valid_extra_textures.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned all the textures", func() {
+			assertEvent(mtl.BumpTextureEvent{
+				TexturePath: "bump_lower.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+			bumpOptions := mtl.DefaultTextureOptions()
+			bumpOptions.BumpMultiplier = 2.0
+			assertEvent(mtl.BumpTextureEvent{
+				TexturePath: "bump_mixed.png",
+				Options:     bumpOptions,
+			})
+			assertEvent(mtl.BumpTextureEvent{
+				TexturePath: "bump_upper.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+			assertEvent(mtl.DisplacementTextureEvent{
+				TexturePath: "displacement.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+			assertEvent(mtl.DecalTextureEvent{
+				TexturePath: "decal.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+			sphereOptions := mtl.DefaultTextureOptions()
+			sphereOptions.Type = "sphere"
+			assertEvent(mtl.ReflectionTextureEvent{
+				TexturePath: "sphere.png",
+				Options:     sphereOptions,
+			})
+			topOptions := mtl.DefaultTextureOptions()
+			topOptions.Type = "cube_top"
+			assertEvent(mtl.ReflectionTextureEvent{
+				TexturePath: "top.png",
+				Options:     topOptions,
+			})
+			leftOptions := mtl.DefaultTextureOptions()
+			leftOptions.Type = "cube_left"
+			assertEvent(mtl.ReflectionTextureEvent{
+				TexturePath: "left.png",
+				Options:     leftOptions,
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("reading optical declarations", func() {
 		BeforeEach(func() {
-			testFile = "valid_unsupported_declarations.mtl"
+			testFile = "valid_optical_declarations.mtl"
 		})
 
 		itShouldNotHaveReturnedAnError()
 
-		It("should not have scanned anything", func() {
+		It("should have scanned all the declarations", func() {
+			assertEvent(mtl.DissolveEvent{
+				Amount: 0.4,
+			})
+			assertEvent(mtl.DissolveEvent{
+				Amount: 0.6,
+				Halo:   true,
+			})
+			assertEvent(mtl.TransparencyEvent{
+				Amount: 0.25,
+			})
+			assertEvent(mtl.OpticalDensityEvent{
+				Amount: 1.5,
+			})
+			assertEvent(mtl.SharpnessEvent{
+				Amount: 200.0,
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("reading unknown commands", func() {
+		BeforeEach(func() {
+			testFile = "valid_unknown_commands.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned them as unknown commands", func() {
+			assertEvent(common.CommentEvent{
+				Comment: "Vendor specific commands are not supported",
+			})
+			assertEvent(common.UnknownCommandEvent{
+				CommandName: "vendor_version",
+				Params:      []string{"2"},
+			})
+			assertEvent(mtl.MaterialEvent{
+				MaterialName: "Custom",
+			})
+			assertEvent(mtl.RGBDiffuseColorEvent{
+				R: 1.0, G: 0.5, B: 0.25,
+			})
+			assertEvent(common.UnknownCommandEvent{
+				CommandName: "map_custom",
+				Params:      []string{"texture.png", "-o", "1"},
+			})
+			assertEvent(common.UnknownCommandEvent{
+				CommandName: "double_sided",
+				Params:      []string{},
+			})
 			assertNoMoreEvents()
 		})
 	})
 
+	When("reading PBR declarations", func() {
+		BeforeEach(func() {
+			testFile = "valid_pbr.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned all the declarations", func() {
+			assertEvent(mtl.RoughnessEvent{
+				Amount: 0.25,
+			})
+			assertEvent(mtl.MetallicEvent{
+				Amount: 1.0,
+			})
+			assertEvent(mtl.SheenEvent{
+				Amount: 0.1,
+			})
+			assertEvent(mtl.ClearcoatThicknessEvent{
+				Amount: 0.2,
+			})
+			assertEvent(mtl.ClearcoatRoughnessEvent{
+				Amount: 0.3,
+			})
+			assertEvent(mtl.AnisotropyEvent{
+				Amount: 0.4,
+			})
+			assertEvent(mtl.AnisotropyRotationEvent{
+				Amount: 0.5,
+			})
+			assertEvent(mtl.RoughnessTextureEvent{
+				TexturePath: "roughness.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+			assertEvent(mtl.MetallicTextureEvent{
+				TexturePath: "metallic.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+			assertEvent(mtl.SheenTextureEvent{
+				TexturePath: "sheen.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+			assertEvent(mtl.RMATextureEvent{
+				TexturePath: "rma.png",
+				Options:     mtl.DefaultTextureOptions(),
+			})
+			normalOptions := mtl.DefaultTextureOptions()
+			normalOptions.BumpMultiplier = 0.5
+			assertEvent(mtl.NormalTextureEvent{
+				TexturePath: "normal.png",
+				Options:     normalOptions,
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("reading spectral and xyz colors", func() {
+		BeforeEach(func() {
+			testFile = "valid_alternative_colors.mtl"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned all the colors", func() {
+			assertEvent(mtl.SpectralAmbientColorEvent{
+				FilePath: "ambient.rfl",
+				Factor:   0.5,
+			})
+			assertEvent(mtl.SpectralDiffuseColorEvent{
+				FilePath: "diffuse.rfl",
+				Factor:   0.5,
+			})
+			assertEvent(mtl.SpectralSpecularColorEvent{
+				FilePath: "specular.rfl",
+				Factor:   0.5,
+			})
+			assertEvent(mtl.SpectralTransmissionFilterEvent{
+				FilePath: "transmission.rfl",
+				Factor:   0.5,
+			})
+			assertEvent(mtl.XYZAmbientColorEvent{
+				X: 0.3, Y: 0.4, Z: 0.5,
+			})
+			assertEvent(mtl.XYZDiffuseColorEvent{
+				X: 0.3, Y: 0.4, Z: 0.5,
+			})
+			assertEvent(mtl.XYZSpecularColorEvent{
+				X: 0.3, Y: 0.4, Z: 0.5,
+			})
+			assertEvent(mtl.XYZTransmissionFilterEvent{
+				X: 0.3, Y: 0.4, Z: 0.5,
+			})
+			assertEvent(mtl.XYZEmissiveColorEvent{
+				X: 0.3, Y: 0.4, Z: 0.5,
+			})
+			assertEvent(mtl.SpectralEmissiveColorEvent{
+				FilePath: "emissive.rfl",
+				Factor:   1.0,
+			})
+			assertEvent(mtl.XYZDiffuseColorEvent{
+				X: 0.2, Y: 0.2, Z: 0.2,
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("reading spectral color without filename param", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_spectral_color_filename.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("reading xyz color with invalid values", func() {
+		BeforeEach(func() {
+			testFile = "error_invalid_xyz_color_values.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 12, Command: "Kd",
+		})
+	})
+
 	When("reading material without name", func() {
 		BeforeEach(func() {
 			testFile = "error_missing_material_name.mtl"
 		})
 
 		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 1, Command: "newmtl",
+		})
 	})
 
 	When("reading ambient color without enough values", func() {
@@ -331,6 +735,10 @@ var _ = Describe("Scanner", func() {
 		})
 
 		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 4, Command: "Kd",
+		})
 	})
 
 	When("reading specular color without enough values", func() {
@@ -381,6 +789,90 @@ var _ = Describe("Scanner", func() {
 		itShouldHaveReturnedAnError()
 	})
 
+	When("reading reflection texture with invalid type", func() {
+		BeforeEach(func() {
+			testFile = "error_invalid_reflection_type.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 12, Command: "refl",
+		})
+	})
+
+	When("reading displacement texture without filename param", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_displacement_texture_filename.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("reading halo dissolve without value", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_halo_dissolve_value.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("reading transparency without value", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_transparency_value.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("reading optical density with invalid value", func() {
+		BeforeEach(func() {
+			testFile = "error_invalid_optical_density_value.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 4, Command: "Ni",
+		})
+	})
+
+	When("reading sharpness without value", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_sharpness_value.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("reading roughness without value", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_roughness_value.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("reading metallic with invalid value", func() {
+		BeforeEach(func() {
+			testFile = "error_invalid_metallic_value.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 4, Command: "Pm",
+		})
+	})
+
+	When("reading normal texture without filename param", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_normal_texture_filename.mtl"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
 	When("reading dissolve without value", func() {
 		BeforeEach(func() {
 			testFile = "error_missing_dissolve_value.mtl"
@@ -485,6 +977,216 @@ var _ = Describe("Scanner", func() {
 		itShouldHaveReturnedAnError()
 	})
 
+	When("scanning in strict mode", func() {
+		BeforeEach(func() {
+			options := common.DefaultScanOptions()
+			options.Mode = common.ScanModeStrict
+			scanner = mtl.NewScannerWithOptions(options)
+		})
+
+		When("a valid file is scanned", func() {
+			BeforeEach(func() {
+				testFile = "valid_basic.mtl"
+			})
+
+			itShouldNotHaveReturnedAnError()
+		})
+
+		When("a file with unknown commands is scanned", func() {
+			BeforeEach(func() {
+				testFile = "valid_unknown_commands.mtl"
+			})
+
+			itShouldHaveReturnedAParseError(common.ParseError{
+				Line: 2, Column: 1, Command: "vendor_version",
+			})
+		})
+
+		When("a file with material names that contain spaces is scanned", func() {
+			BeforeEach(func() {
+				testFile = "valid_material_name_spaces.mtl"
+			})
+
+			itShouldNotHaveReturnedAnError()
+		})
+
+		When("a file with excess parameters is scanned", func() {
+			BeforeEach(func() {
+				testFile = "error_excess_parameters.mtl"
+			})
+
+			itShouldHaveReturnedAParseError(common.ParseError{
+				Line: 2, Column: 7, Command: "Ns",
+			})
+		})
+
+		When("a file with an out of range dissolve is scanned", func() {
+			BeforeEach(func() {
+				testFile = "error_out_of_range_dissolve.mtl"
+			})
+
+			itShouldHaveReturnedAParseError(common.ParseError{
+				Line: 2, Column: 3, Command: "d",
+			})
+		})
+	})
+
+	When("a file with malformed lines is scanned", func() {
+		BeforeEach(func() {
+			testFile = "error_malformed_lines.mtl"
+		})
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 2, Column: 8, Command: "Kd",
+		})
+
+		When("scanning in lenient mode", func() {
+			var warnings []common.Warning
+
+			BeforeEach(func() {
+				warnings = nil
+				options := common.DefaultScanOptions()
+				options.Mode = common.ScanModeLenient
+				options.WarningHandler = func(warning common.Warning) {
+					warnings = append(warnings, warning)
+				}
+				scanner = mtl.NewScannerWithOptions(options)
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have skipped the malformed lines", func() {
+				assertEvent(mtl.MaterialEvent{
+					MaterialName: "Test",
+				})
+				assertEvent(mtl.SpecularExponentEvent{
+					Amount: 10.0,
+				})
+				assertEvent(mtl.DissolveEvent{
+					Amount: 0.5,
+				})
+				assertNoMoreEvents()
+			})
+
+			It("should have reported the malformed lines", func() {
+				Expect(warnings).To(HaveLen(2))
+				Expect(warnings[0].Line).To(Equal(2))
+				Expect(warnings[0].Column).To(Equal(8))
+				Expect(warnings[0].Command).To(Equal("Kd"))
+				Expect(warnings[1].Line).To(Equal(4))
+				Expect(warnings[1].Column).To(Equal(8))
+				Expect(warnings[1].Command).To(Equal("map_Kd"))
+				Expect(warnings[1].Err).To(MatchError(common.ErrInvalid))
+			})
+		})
+	})
+
+	When("the handler stops the scanning", func() {
+		BeforeEach(func() {
+			testFile = "valid_basic.mtl"
+			handler = func(event common.Event) error {
+				if err := trackedHandler.Handle(event); err != nil {
+					return err
+				}
+				if _, ok := event.(mtl.MaterialEvent); ok {
+					return common.ErrStop
+				}
+				return nil
+			}
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should not have scanned past the stopping event", func() {
+			assertEvent(common.CommentEvent{
+				Comment: "This is the beginning of this MTL file.",
+			})
+			assertEvent(mtl.MaterialEvent{
+				MaterialName: "MyMaterial",
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("scanning with a context", func() {
+		var (
+			scanCtx    context.Context
+			cancelScan context.CancelFunc
+		)
+
+		scanWithContext := func() error {
+			file, err := os.Open(filepath.Join("testdata", "valid_basic.mtl"))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			options := common.DefaultScanOptions()
+			options.ContextCheckInterval = 1
+			return mtl.NewScannerWithOptions(options).(common.ContextScanner).ScanContext(scanCtx, file, handler)
+		}
+
+		BeforeEach(func() {
+			testFile = "valid_basic.mtl"
+			scanCtx, cancelScan = context.WithCancel(context.Background())
+			DeferCleanup(func() {
+				cancelScan()
+			})
+		})
+
+		It("should scan the whole file", func() {
+			trackedHandler.Events = nil
+			Expect(scanWithContext()).To(Succeed())
+			Expect(trackedHandler.Events).ToNot(BeEmpty())
+		})
+
+		It("should not scan when the context is cancelled", func() {
+			trackedHandler.Events = nil
+			cancelScan()
+			Expect(scanWithContext()).To(MatchError(context.Canceled))
+			Expect(trackedHandler.Events).To(BeEmpty())
+		})
+
+		It("should stop scanning when the context is cancelled", func() {
+			trackedHandler.Events = nil
+			handler = func(event common.Event) error {
+				cancelScan()
+				return trackedHandler.Handle(event)
+			}
+			Expect(scanWithContext()).To(MatchError(context.Canceled))
+			Expect(trackedHandler.Events).To(Equal([]common.Event{
+				common.CommentEvent{
+					Comment: "This is the beginning of this MTL file.",
+				},
+			}))
+		})
+	})
+
+	When("scanning with a progress handler", func() {
+		var reports []common.Progress
+
+		BeforeEach(func() {
+			reports = nil
+			testFile = "valid_basic.mtl"
+			options := common.DefaultScanOptions()
+			options.ProgressInterval = -1
+			options.ProgressHandler = func(progress common.Progress) {
+				reports = append(reports, progress)
+			}
+			scanner = mtl.NewScannerWithOptions(options)
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have reported the progress of each line", func() {
+			Expect(reports).To(HaveLen(20))
+			Expect(reports[19]).To(Equal(common.Progress{
+				BytesRead:  388,
+				TotalBytes: 388,
+				Lines:      20,
+				Elements:   16,
+			}))
+		})
+	})
+
 	When("handler returns an error", func() {
 		BeforeEach(func() {
 			handler = errorHandler
@@ -609,5 +1311,21 @@ var _ = Describe("Scanner", func() {
 
 			itShouldHaveReturnedHandlerError()
 		})
+
+		When("on PBR declarations", func() {
+			BeforeEach(func() {
+				testFile = "valid_pbr.mtl"
+			})
+
+			itShouldHaveReturnedHandlerError()
+		})
+
+		When("on unknown commands", func() {
+			BeforeEach(func() {
+				testFile = "valid_unknown_commands.mtl"
+			})
+
+			itShouldHaveReturnedHandlerError()
+		})
 	})
 })
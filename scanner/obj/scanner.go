@@ -1,10 +1,13 @@
 package obj
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/mokiat/go-data-front/common"
+	"github.com/mokiat/go-data-front/internal/progress"
 )
 
 // MaterialLibraryEvent indicates that a material library (MTL resource)
@@ -28,6 +31,11 @@ type MaterialLibraryEvent struct {
 // vertices for a given object have the same dimension
 // (e.g. all vertices have Z and W equal to 0.0 and 1.0 respectively
 // which would mean 2D).
+//
+// Some tools extend the declaration with a vertex color. A declaration
+// with six components is interpreted as `x y z r g b` and one with seven
+// components as `x y z w r g b`. Four components are always interpreted
+// as `x y z w`.
 type VertexEvent struct {
 
 	// X defines the X coordinate of this vertex.
@@ -41,6 +49,20 @@ type VertexEvent struct {
 
 	// W defines the W coordinate of this vertex.
 	W float64
+
+	// R defines the red color component of this vertex.
+	R float64
+
+	// G defines the green color component of this vertex.
+	G float64
+
+	// B defines the blue color component of this vertex.
+	B float64
+
+	// HasColor specifies whether the declaration included
+	// color information. If false, then R, G, and B are
+	// all 0.0.
+	HasColor bool
 }
 
 // TexCoordEvent indicates that a texture coordinate declaration (`vt`) has
@@ -92,12 +114,39 @@ type ObjectEvent struct {
 	ObjectName string
 }
 
+// GroupEvent indicates that a group declaration (`g`) has
+// been scanned.
+//
+// Elements that follow belong to all of the specified groups
+// until another group declaration is scanned.
+type GroupEvent struct {
+
+	// GroupNames holds the names of the declared groups. If the
+	// declaration did not specify any names, then this holds the
+	// single name `default`, as per the OBJ specification.
+	GroupNames []string
+}
+
+// SmoothingGroupEvent indicates that a smoothing group declaration
+// (`s`) has been scanned.
+//
+// Elements that follow belong to the specified smoothing group
+// until another smoothing group declaration is scanned.
+type SmoothingGroupEvent struct {
+
+	// GroupNumber holds the number of the smoothing group. A value
+	// of zero indicates that smoothing is turned off (`s off` or
+	// `s 0`).
+	GroupNumber int64
+}
+
 // MaterialReferenceEvent indicates that a material reference
 // declaration (`usemtl`) has been scanned.
 type MaterialReferenceEvent struct {
 
 	// MaterialName holds the name of the material that should be
-	// used for the rendering of entities that follow.
+	// used for the rendering of entities that follow. The name can
+	// contain spaces.
 	MaterialName string
 }
 
@@ -114,8 +163,35 @@ type FaceStartEvent struct {
 type FaceEndEvent struct {
 }
 
+// LineStartEvent indicates that a line declaration (`l`) is being
+// scanned.
+//
+// Events that follow will concern that specific line up until the
+// point a LineEndEvent is thrown.
+type LineStartEvent struct {
+}
+
+// LineEndEvent indicates that the scanning of the line declaration
+// has completed.
+type LineEndEvent struct {
+}
+
+// PointStartEvent indicates that a point declaration (`p`) is being
+// scanned.
+//
+// Events that follow will concern that specific point declaration
+// up until the point a PointEndEvent is thrown. Each reference set
+// that is scanned in between represents a separate point.
+type PointStartEvent struct {
+}
+
+// PointEndEvent indicates that the scanning of the point declaration
+// has completed.
+type PointEndEvent struct {
+}
+
 // ReferenceSetStartEvent indicates that a reference set (e.g. 1/2/3)
-// is being parsed as part of face scanning.
+// is being parsed as part of face, line, or point scanning.
 //
 // Events that follow will contain reference data, until a
 // ReferenceSetEndEvent is received.
@@ -154,75 +230,194 @@ type NormalReferenceEvent struct {
 
 // NewScanner creates a new Scanner object that can scan through
 // Wavefront OBJ resources.
+//
+// The scanner uses the options returned by common.DefaultScanOptions.
+// The returned Scanner also implements common.PositionedScanner.
 func NewScanner() common.Scanner {
-	return &scanner{}
+	return NewScannerWithOptions(common.DefaultScanOptions())
+}
+
+// NewScannerWithOptions creates a new Scanner object that can scan
+// through Wavefront OBJ resources according to the specified
+// common.ScanOptions.
+//
+// The returned Scanner also implements common.PositionedScanner.
+func NewScannerWithOptions(options common.ScanOptions) common.Scanner {
+	return &scanner{
+		options: &options,
+	}
 }
 
 type scanner struct {
+	options *common.ScanOptions
 }
 
 func (s *scanner) Scan(reader io.Reader, handler common.EventHandler) error {
-	lineScanner := common.NewLineScanner(reader)
+	return s.ScanContext(context.Background(), reader, handler)
+}
+
+func (s *scanner) ScanContext(ctx context.Context, reader io.Reader, handler common.EventHandler) error {
+	return s.ScanPositioned(ctx, reader, func(event common.Event, _ common.EventSource) error {
+		return handler(event)
+	})
+}
+
+func (s *scanner) ScanPositioned(ctx context.Context, reader io.Reader, handler common.PositionedEventHandler) error {
+	err := s.scan(ctx, reader, handler)
+	if errors.Is(err, common.ErrStop) {
+		return nil
+	}
+	return err
+}
 
-	for lineScanner.Scan() {
+func (s *scanner) scan(ctx context.Context, reader io.Reader, handler common.PositionedEventHandler) error {
+	state := &scanState{
+		handler: handler,
+	}
+	tracker := progress.NewTracker(reader, s.options)
+	lineScanner := common.NewLimitedLineScanner(reader, s.options.MaxLineLength)
+
+	interval := max(1, s.options.ContextCheckInterval)
+	for lineCount := 0; lineScanner.Scan(); lineCount++ {
+		if lineCount%interval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
 		line := lineScanner.Line()
+		state.position = line.Position()
+		state.command = ""
+		if line.IsCommand() && !line.IsComment() {
+			state.command = line.CommandName()
+		}
 		switch {
 		case line.IsBlank():
 			// Nothing to do.
 		case line.IsComment():
-			if err := s.processComment(line, handler); err != nil {
+			if err := s.processComment(line, state); err != nil {
 				return err
 			}
 		case line.IsCommand():
-			if err := s.processCommand(line, handler); err != nil {
+			if err := s.processCommand(line, state); err != nil && !s.skipMalformedLine(err) {
 				return err
 			}
 		default:
 			// Ignore line.
 		}
+		tracker.LineScanned(line)
 	}
 
 	if err := lineScanner.Err(); err != nil {
 		return err
 	}
+	tracker.Finish()
 	return nil
 }
 
-func (s *scanner) processComment(line common.Line, handler common.EventHandler) error {
+// scanState holds the state of a single scan, which allows
+// a scanner to be used for multiple scans at the same time.
+type scanState struct {
+	handler       common.PositionedEventHandler
+	position      common.Position
+	command       string
+	referenceSets []referenceIndices
+}
+
+// emit passes the specified event, together with the source
+// of the element that triggered it, to the handler.
+func (s *scanState) emit(event common.Event) error {
+	return s.handler(event, common.EventSource{
+		Position: s.position,
+		Command:  s.command,
+	})
+}
+
+// skipMalformedLine reports the specified error as a warning and
+// returns true, if the scanner is lenient and the error was caused
+// by a malformed line.
+func (s *scanner) skipMalformedLine(err error) bool {
+	if s.options.Mode != common.ScanModeLenient {
+		return false
+	}
+	// Handler errors are not wrapped, so only errors that were
+	// produced by the scanner itself are matched here.
+	parseErr, ok := err.(*common.ParseError)
+	if !ok {
+		return false
+	}
+	if s.options.WarningHandler != nil {
+		s.options.WarningHandler(common.Warning(*parseErr))
+	}
+	return true
+}
+
+func (s *scanner) processComment(line common.Line, state *scanState) error {
 	event := common.CommentEvent{
 		Comment: line.Comment(),
 	}
-	return handler(event)
+	return state.emit(event)
+}
+
+func (s *scanner) processUnknownCommand(line common.Line, state *scanState) error {
+	// Comment directives are comments as far as the specification
+	// is concerned, so they are not rejected in strict mode.
+	if s.options.Mode == common.ScanModeStrict && !line.IsCommentDirective() {
+		return common.NewParseError(line, fmt.Errorf("%w: unknown command", common.ErrInvalid))
+	}
+	event := common.UnknownCommandEvent{
+		CommandName: line.CommandName(),
+		Params:      line.Params(),
+	}
+	return state.emit(event)
 }
 
-func (s *scanner) processCommand(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processCommand(line common.Line, state *scanState) error {
 	switch {
 	case line.HasCommandName("mtllib"):
-		return s.processMaterialLibrary(line, handler)
+		return s.processMaterialLibrary(line, state)
 	case line.HasCommandName("v"):
-		return s.processVertex(line, handler)
+		return s.processVertex(line, state)
 	case line.HasCommandName("vt"):
-		return s.processTexCoord(line, handler)
+		return s.processTexCoord(line, state)
 	case line.HasCommandName("vn"):
-		return s.processNormal(line, handler)
+		return s.processNormal(line, state)
 	case line.HasCommandName("o"):
-		return s.processObject(line, handler)
+		return s.processObject(line, state)
+	case line.HasCommandName("g"):
+		return s.processGroup(line, state)
+	case line.HasCommandName("s"):
+		return s.processSmoothingGroup(line, state)
 	case line.HasCommandName("usemtl"):
-		return s.processMaterialReference(line, handler)
+		return s.processMaterialReference(line, state)
 	case line.HasCommandName("f"):
-		return s.processFace(line, handler)
+		return s.processElement(line, FaceStartEvent{}, FaceEndEvent{}, state)
+	case line.HasCommandName("l"):
+		return s.processElement(line, LineStartEvent{}, LineEndEvent{}, state)
+	case line.HasCommandName("p"):
+		return s.processElement(line, PointStartEvent{}, PointEndEvent{}, state)
 	default:
-		return nil
+		return s.processUnknownCommand(line, state)
 	}
 }
 
-func (s *scanner) processMaterialLibrary(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processMaterialLibrary(line common.Line, state *scanState) error {
+	if s.options.SingleMaterialLibraryPath {
+		if line.ParamCount() == 0 {
+			return nil
+		}
+		event := MaterialLibraryEvent{
+			FilePath: line.Remainder(0),
+		}
+		state.position = line.ParamPosition(0)
+		return state.emit(event)
+	}
 	for i := 0; i < line.ParamCount(); i++ {
 		path := line.StringParam(i)
 		event := MaterialLibraryEvent{
 			FilePath: path,
 		}
-		err := handler(event)
+		state.position = line.ParamPosition(i)
+		err := state.emit(event)
 		if err != nil {
 			return err
 		}
@@ -230,9 +425,9 @@ func (s *scanner) processMaterialLibrary(line common.Line, handler common.EventH
 	return nil
 }
 
-func (s *scanner) processVertex(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processVertex(line common.Line, state *scanState) error {
 	if line.ParamCount() < 3 {
-		return fmt.Errorf("%w: insufficient vertex data", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: insufficient vertex data", common.ErrInvalid))
 	}
 	var err error
 	event := VertexEvent{
@@ -240,28 +435,73 @@ func (s *scanner) processVertex(line common.Line, handler common.EventHandler) e
 	}
 	event.X, err = line.FloatParam(0)
 	if err != nil {
-		return err
+		return common.NewParamParseError(line, 0, err)
 	}
 	event.Y, err = line.FloatParam(1)
 	if err != nil {
-		return err
+		return common.NewParamParseError(line, 1, err)
 	}
 	event.Z, err = line.FloatParam(2)
 	if err != nil {
-		return err
+		return common.NewParamParseError(line, 2, err)
 	}
-	if line.ParamCount() >= 4 {
+	// The five component form is not valid, since a color needs
+	// all three of its components.
+	switch count := line.ParamCount(); {
+	case count == 5:
+		if err := s.checkParamCount(line, 4); err != nil {
+			return err
+		}
+	default:
+		if err := s.checkParamCount(line, 7); err != nil {
+			return err
+		}
+	}
+	colorIndex := -1
+	switch count := line.ParamCount(); {
+	case count == 6:
+		colorIndex = 3
+	case count >= 7:
 		event.W, err = line.FloatParam(3)
 		if err != nil {
-			return err
+			return common.NewParamParseError(line, 3, err)
+		}
+		colorIndex = 4
+	case count >= 4:
+		event.W, err = line.FloatParam(3)
+		if err != nil {
+			return common.NewParamParseError(line, 3, err)
+		}
+	}
+	if colorIndex >= 0 {
+		event.R, err = line.FloatParam(colorIndex)
+		if err != nil {
+			return common.NewParamParseError(line, colorIndex, err)
+		}
+		event.G, err = line.FloatParam(colorIndex + 1)
+		if err != nil {
+			return common.NewParamParseError(line, colorIndex+1, err)
+		}
+		event.B, err = line.FloatParam(colorIndex + 2)
+		if err != nil {
+			return common.NewParamParseError(line, colorIndex+2, err)
 		}
+		for i, component := range []float64{event.R, event.G, event.B} {
+			if err := s.checkParamRange(line, colorIndex+i, component, 0.0, 1.0); err != nil {
+				return err
+			}
+		}
+		event.HasColor = true
 	}
-	return handler(event)
+	return state.emit(event)
 }
 
-func (s *scanner) processTexCoord(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processTexCoord(line common.Line, state *scanState) error {
 	if line.ParamCount() == 0 {
-		return fmt.Errorf("%w: insufficient texture coordinate data", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: insufficient texture coordinate data", common.ErrInvalid))
+	}
+	if err := s.checkParamCount(line, 3); err != nil {
+		return err
 	}
 
 	var err error
@@ -270,26 +510,29 @@ func (s *scanner) processTexCoord(line common.Line, handler common.EventHandler)
 	}
 	event.U, err = line.FloatParam(0)
 	if err != nil {
-		return err
+		return common.NewParamParseError(line, 0, err)
 	}
 	if line.ParamCount() >= 2 {
 		event.V, err = line.FloatParam(1)
 		if err != nil {
-			return err
+			return common.NewParamParseError(line, 1, err)
 		}
 	}
 	if line.ParamCount() >= 3 {
 		event.W, err = line.FloatParam(2)
 		if err != nil {
-			return err
+			return common.NewParamParseError(line, 2, err)
 		}
 	}
-	return handler(event)
+	return state.emit(event)
 }
 
-func (s *scanner) processNormal(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processNormal(line common.Line, state *scanState) error {
 	if line.ParamCount() < 3 {
-		return fmt.Errorf("%w: insufficient normal data", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: insufficient normal data", common.ErrInvalid))
+	}
+	if err := s.checkParamCount(line, 3); err != nil {
+		return err
 	}
 	var err error
 	event := NormalEvent{
@@ -297,91 +540,205 @@ func (s *scanner) processNormal(line common.Line, handler common.EventHandler) e
 	}
 	event.X, err = line.FloatParam(0)
 	if err != nil {
-		return err
+		return common.NewParamParseError(line, 0, err)
 	}
 	event.Y, err = line.FloatParam(1)
 	if err != nil {
-		return err
+		return common.NewParamParseError(line, 1, err)
 	}
 	event.Z, err = line.FloatParam(2)
 	if err != nil {
-		return err
+		return common.NewParamParseError(line, 2, err)
 	}
-	return handler(event)
+	return state.emit(event)
 }
 
-func (s *scanner) processObject(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processObject(line common.Line, state *scanState) error {
 	if line.ParamCount() == 0 {
-		return fmt.Errorf("%w: no name specified for object", common.ErrInvalid)
+		return common.NewParseError(line, fmt.Errorf("%w: no name specified for object", common.ErrInvalid))
+	}
+	if err := s.checkParamCount(line, 1); err != nil {
+		return err
 	}
 	name := line.StringParam(0)
 	event := ObjectEvent{
 		ObjectName: name,
 	}
-	return handler(event)
+	return state.emit(event)
+}
+
+func (s *scanner) processGroup(line common.Line, state *scanState) error {
+	event := GroupEvent{}
+	for i := 0; i < line.ParamCount(); i++ {
+		event.GroupNames = append(event.GroupNames, line.StringParam(i))
+	}
+	if len(event.GroupNames) == 0 {
+		event.GroupNames = []string{"default"}
+	}
+	return state.emit(event)
 }
 
-func (s *scanner) processMaterialReference(line common.Line, handler common.EventHandler) error {
+func (s *scanner) processSmoothingGroup(line common.Line, state *scanState) error {
+	if line.ParamCount() == 0 {
+		return common.NewParseError(line, fmt.Errorf("%w: no smoothing group specified", common.ErrInvalid))
+	}
+	if err := s.checkParamCount(line, 1); err != nil {
+		return err
+	}
+	event := SmoothingGroupEvent{}
+	if line.StringParam(0) != "off" {
+		number, err := line.IntParam(0)
+		if err != nil {
+			return common.NewParamParseError(line, 0, err)
+		}
+		if number < 0 {
+			return common.NewParamParseError(line, 0, fmt.Errorf("%w: negative smoothing group", common.ErrInvalid))
+		}
+		event.GroupNumber = number
+	}
+	return state.emit(event)
+}
+
+func (s *scanner) processMaterialReference(line common.Line, state *scanState) error {
 	event := MaterialReferenceEvent{}
 	if line.ParamCount() > 0 {
-		event.MaterialName = line.StringParam(0)
+		// The name is the only argument and can contain spaces.
+		event.MaterialName = line.Remainder(0)
 	}
-	return handler(event)
+	return state.emit(event)
 }
 
-func (s *scanner) processFace(line common.Line, handler common.EventHandler) error {
-	err := handler(FaceStartEvent{})
+func (s *scanner) processElement(line common.Line, startEvent, endEvent common.Event, state *scanState) error {
+	// All reference sets are parsed upfront, so that no events
+	// are emitted for an element that is malformed.
+	state.referenceSets = state.referenceSets[:0]
+	for i := 0; i < line.ParamCount(); i++ {
+		indices, err := s.parseReferenceSet(line, i)
+		if err != nil {
+			return err
+		}
+		state.referenceSets = append(state.referenceSets, indices)
+	}
+
+	err := state.emit(startEvent)
 	if err != nil {
 		return err
 	}
 
-	for i := 0; i < line.ParamCount(); i++ {
-		err := s.processReferenceSet(line.ReferenceSetParam(i), handler)
+	for i, indices := range state.referenceSets {
+		state.position = line.ParamPosition(i)
+		err := s.processReferenceSet(indices, state)
 		if err != nil {
 			return err
 		}
 	}
 
-	return handler(FaceEndEvent{})
+	state.position = line.Position()
+	return state.emit(endEvent)
+}
+
+// referenceIndices holds the parsed indices of a reference set.
+type referenceIndices struct {
+	vertexIndex   int64
+	texCoordIndex int64
+	normalIndex   int64
+	hasTexCoord   bool
+	hasNormal     bool
 }
 
-func (s *scanner) processReferenceSet(referenceSet common.ReferenceSet, handler common.EventHandler) error {
-	err := handler(ReferenceSetStartEvent{})
+func (s *scanner) parseReferenceSet(line common.Line, index int) (referenceIndices, error) {
+	var result referenceIndices
+
+	references := line.ReferenceSetParam(index)
+	if references.Count() == 0 {
+		return referenceIndices{}, common.NewParamParseError(line, index, fmt.Errorf("%w: reference set has no references", common.ErrInvalid))
+	}
+	if s.options.Mode == common.ScanModeStrict && references.Count() > 3 {
+		return referenceIndices{}, common.NewParamParseError(line, index, fmt.Errorf("%w: reference set has too many references", common.ErrInvalid))
+	}
+
+	var err error
+	result.vertexIndex, err = references.IntReference(0)
 	if err != nil {
-		return err
+		return referenceIndices{}, common.NewParamParseError(line, index, err)
+	}
+
+	if (references.Count() > 1) && !references.IsBlank(1) {
+		result.texCoordIndex, err = references.IntReference(1)
+		if err != nil {
+			return referenceIndices{}, common.NewParamParseError(line, index, err)
+		}
+		result.hasTexCoord = true
 	}
 
-	if referenceSet.Count() == 0 {
-		return fmt.Errorf("%w: reference set has no references", common.ErrInvalid)
+	if (references.Count() > 2) && !references.IsBlank(2) {
+		result.normalIndex, err = references.IntReference(2)
+		if err != nil {
+			return referenceIndices{}, common.NewParamParseError(line, index, err)
+		}
+		result.hasNormal = true
 	}
 
-	vertexIndex, err := referenceSet.IntReference(0)
+	// Indices start from 1, with negative ones being relative
+	// to the end, so zero never points to valid data.
+	if s.options.Mode == common.ScanModeStrict {
+		if result.vertexIndex == 0 || (result.hasTexCoord && result.texCoordIndex == 0) || (result.hasNormal && result.normalIndex == 0) {
+			return referenceIndices{}, common.NewParamParseError(line, index, fmt.Errorf("%w: reference index is zero", common.ErrInvalid))
+		}
+	}
+
+	return result, nil
+}
+
+func (s *scanner) processReferenceSet(indices referenceIndices, state *scanState) error {
+	err := state.emit(ReferenceSetStartEvent{})
 	if err != nil {
 		return err
 	}
-	handler(VertexReferenceEvent{
-		VertexIndex: vertexIndex,
+
+	err = state.emit(VertexReferenceEvent{
+		VertexIndex: indices.vertexIndex,
 	})
+	if err != nil {
+		return err
+	}
 
-	if (referenceSet.Count() > 1) && !referenceSet.IsBlank(1) {
-		texCoordIndex, err := referenceSet.IntReference(1)
+	if indices.hasTexCoord {
+		err = state.emit(TexCoordReferenceEvent{
+			TexCoordIndex: indices.texCoordIndex,
+		})
 		if err != nil {
 			return err
 		}
-		handler(TexCoordReferenceEvent{
-			TexCoordIndex: texCoordIndex,
-		})
 	}
 
-	if (referenceSet.Count() > 2) && !referenceSet.IsBlank(2) {
-		normalIndex, err := referenceSet.IntReference(2)
+	if indices.hasNormal {
+		err = state.emit(NormalReferenceEvent{
+			NormalIndex: indices.normalIndex,
+		})
 		if err != nil {
 			return err
 		}
-		handler(NormalReferenceEvent{
-			NormalIndex: normalIndex,
-		})
 	}
 
-	return handler(ReferenceSetEndEvent{})
+	return state.emit(ReferenceSetEndEvent{})
+}
+
+// checkParamCount returns an error if the scanner is strict and
+// the line has more than the specified number of parameters.
+func (s *scanner) checkParamCount(line common.Line, maxCount int) error {
+	if s.options.Mode != common.ScanModeStrict || line.ParamCount() <= maxCount {
+		return nil
+	}
+	return common.NewParamParseError(line, maxCount, fmt.Errorf("%w: unexpected parameter", common.ErrInvalid))
+}
+
+// checkParamRange returns an error if the scanner is strict and
+// the value of the parameter at the specified index is outside
+// of the specified range.
+func (s *scanner) checkParamRange(line common.Line, index int, value, minValue, maxValue float64) error {
+	if s.options.Mode != common.ScanModeStrict || (value >= minValue && value <= maxValue) {
+		return nil
+	}
+	return common.NewParamParseError(line, index, fmt.Errorf("%w: value %v is outside of the range [%v, %v]", common.ErrInvalid, value, minValue, maxValue))
 }
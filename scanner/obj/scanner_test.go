@@ -1,8 +1,13 @@
 package obj_test
 
 import (
+	"context"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -15,6 +20,7 @@ import (
 var _ = Describe("Scanner", func() {
 	var (
 		testFile       string
+		scanner        common.Scanner
 		handler        common.EventHandler
 		trackedHandler *testutil.EventHandlerTracker
 		eventCounter   int
@@ -34,6 +40,17 @@ var _ = Describe("Scanner", func() {
 		})
 	}
 
+	itShouldHaveReturnedAParseError := func(expected common.ParseError) {
+		GinkgoHelper()
+		It("should have returned a parse error", func() {
+			var parseErr *common.ParseError
+			Expect(errors.As(scanErr, &parseErr)).To(BeTrue())
+			Expect(parseErr.Line).To(Equal(expected.Line))
+			Expect(parseErr.Column).To(Equal(expected.Column))
+			Expect(parseErr.Command).To(Equal(expected.Command))
+		})
+	}
+
 	assertEvent := func(expected interface{}) {
 		GinkgoHelper()
 		Expect(len(trackedHandler.Events)).To(BeNumerically(">", eventCounter))
@@ -63,11 +80,11 @@ var _ = Describe("Scanner", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer file.Close()
 
-		scanner := obj.NewScanner()
 		scanErr = scanner.Scan(file, handler)
 	})
 
 	BeforeEach(func() {
+		scanner = obj.NewScanner()
 		trackedHandler = new(testutil.EventHandlerTracker)
 		eventCounter = 0
 
@@ -221,6 +238,88 @@ var _ = Describe("Scanner", func() {
 			assertNoMoreEvents()
 		})
 	})
+
+	When("a file with vertex colors is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_vertex_colors.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned the vertices", func() {
+			assertEvent(obj.VertexEvent{
+				X: 1.0, Y: 2.0, Z: 3.0, W: 1.0,
+				R: 0.1, G: 0.2, B: 0.3, HasColor: true,
+			})
+			assertEvent(obj.VertexEvent{
+				X: 4.0, Y: 5.0, Z: 6.0, W: 0.5,
+				R: 0.4, G: 0.5, B: 0.6, HasColor: true,
+			})
+			assertEvent(obj.VertexEvent{
+				X: 7.0, Y: 8.0, Z: 9.0, W: 2.0,
+			})
+			assertNoMoreEvents()
+		})
+	})
+	When("a file with comment directives is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_comment_directives.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned them as unknown commands", func() {
+			assertEvent(common.CommentEvent{
+				Comment: "ZBrush vertex colors are stored as comment directives",
+			})
+			assertEvent(obj.VertexEvent{
+				X: 1.0, Y: 2.0, Z: 3.0, W: 1.0,
+			})
+			assertEvent(common.UnknownCommandEvent{
+				CommandName: "#MRGB",
+				Params:      []string{"ff112233ff445566"},
+			})
+			assertEvent(common.CommentEvent{
+				Comment: "MRGBA is a regular comment",
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("a file with unknown commands is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_unknown_commands.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned them as unknown commands", func() {
+			assertEvent(common.CommentEvent{
+				Comment: "Free-form and vendor specific commands are not supported",
+			})
+			assertEvent(obj.VertexEvent{
+				X: 1.0, Y: 2.0, Z: 3.0, W: 1.0,
+			})
+			assertEvent(common.UnknownCommandEvent{
+				CommandName: "cstype",
+				Params:      []string{"bspline"},
+			})
+			assertEvent(common.UnknownCommandEvent{
+				CommandName: "deg",
+				Params:      []string{"3"},
+			})
+			assertEvent(common.UnknownCommandEvent{
+				CommandName: "bevel",
+				Params:      []string{"off"},
+			})
+			assertEvent(common.UnknownCommandEvent{
+				CommandName: "stech",
+				Params:      []string{"cparm", "0.5"},
+			})
+			assertNoMoreEvents()
+		})
+	})
+
 	When("a file with all kinds of texture coordinates is scanned", func() {
 		BeforeEach(func() {
 			testFile = "valid_texcoords.obj"
@@ -277,6 +376,51 @@ var _ = Describe("Scanner", func() {
 			assertNoMoreEvents()
 		})
 	})
+	When("a file with all kinds of groups is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_groups.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned the groups", func() {
+			assertEvent(obj.GroupEvent{
+				GroupNames: []string{"single"},
+			})
+			assertEvent(obj.GroupEvent{
+				GroupNames: []string{"first", "second"},
+			})
+			assertEvent(obj.GroupEvent{
+				GroupNames: []string{"default"},
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("a file with all kinds of smoothing groups is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_smoothing_groups.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned the smoothing groups", func() {
+			assertEvent(obj.SmoothingGroupEvent{
+				GroupNumber: 1,
+			})
+			assertEvent(obj.SmoothingGroupEvent{
+				GroupNumber: 0,
+			})
+			assertEvent(obj.SmoothingGroupEvent{
+				GroupNumber: 0,
+			})
+			assertEvent(obj.SmoothingGroupEvent{
+				GroupNumber: 12,
+			})
+			assertNoMoreEvents()
+		})
+	})
+
 	When("a file with all kinds of coord references is scanned", func() {
 		BeforeEach(func() {
 			testFile = "valid_faces.obj"
@@ -401,6 +545,92 @@ var _ = Describe("Scanner", func() {
 		})
 	})
 
+	When("a file with lines is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_lines.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned them correctly", func() {
+			// First line
+			assertEvent(obj.LineStartEvent{})
+			assertEvent(obj.ReferenceSetStartEvent{})
+			assertEvent(obj.VertexReferenceEvent{
+				VertexIndex: 1,
+			})
+			assertEvent(obj.ReferenceSetEndEvent{})
+			assertEvent(obj.ReferenceSetStartEvent{})
+			assertEvent(obj.VertexReferenceEvent{
+				VertexIndex: 2,
+			})
+			assertEvent(obj.ReferenceSetEndEvent{})
+			assertEvent(obj.ReferenceSetStartEvent{})
+			assertEvent(obj.VertexReferenceEvent{
+				VertexIndex: 3,
+			})
+			assertEvent(obj.ReferenceSetEndEvent{})
+			assertEvent(obj.LineEndEvent{})
+
+			// Second line
+			assertEvent(obj.LineStartEvent{})
+			assertEvent(obj.ReferenceSetStartEvent{})
+			assertEvent(obj.VertexReferenceEvent{
+				VertexIndex: 4,
+			})
+			assertEvent(obj.TexCoordReferenceEvent{
+				TexCoordIndex: 1,
+			})
+			assertEvent(obj.ReferenceSetEndEvent{})
+			assertEvent(obj.ReferenceSetStartEvent{})
+			assertEvent(obj.VertexReferenceEvent{
+				VertexIndex: 5,
+			})
+			assertEvent(obj.TexCoordReferenceEvent{
+				TexCoordIndex: 2,
+			})
+			assertEvent(obj.ReferenceSetEndEvent{})
+			assertEvent(obj.LineEndEvent{})
+
+			assertNoMoreEvents()
+		})
+	})
+
+	When("a file with points is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_points.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned them correctly", func() {
+			// First point declaration
+			assertEvent(obj.PointStartEvent{})
+			assertEvent(obj.ReferenceSetStartEvent{})
+			assertEvent(obj.VertexReferenceEvent{
+				VertexIndex: 1,
+			})
+			assertEvent(obj.ReferenceSetEndEvent{})
+			assertEvent(obj.PointEndEvent{})
+
+			// Second point declaration
+			assertEvent(obj.PointStartEvent{})
+			assertEvent(obj.ReferenceSetStartEvent{})
+			assertEvent(obj.VertexReferenceEvent{
+				VertexIndex: 2,
+			})
+			assertEvent(obj.ReferenceSetEndEvent{})
+			assertEvent(obj.ReferenceSetStartEvent{})
+			assertEvent(obj.VertexReferenceEvent{
+				VertexIndex: -1,
+			})
+			assertEvent(obj.ReferenceSetEndEvent{})
+			assertEvent(obj.PointEndEvent{})
+
+			assertNoMoreEvents()
+		})
+	})
+
 	When("a file with all kinds of material libraries is scanned", func() {
 		BeforeEach(func() {
 			testFile = "valid_material_libraries.obj"
@@ -422,6 +652,50 @@ var _ = Describe("Scanner", func() {
 		})
 	})
 
+	When("a file with material library paths that contain spaces is scanned", func() {
+		BeforeEach(func() {
+			testFile = "valid_material_library_spaces.obj"
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have scanned each word as a material library", func() {
+			assertEvent(obj.MaterialLibraryEvent{
+				FilePath: "my",
+			})
+			assertEvent(obj.MaterialLibraryEvent{
+				FilePath: "materials.mtl",
+			})
+			assertEvent(obj.MaterialLibraryEvent{
+				FilePath: "other",
+			})
+			assertEvent(obj.MaterialLibraryEvent{
+				FilePath: "library.mtl",
+			})
+			assertNoMoreEvents()
+		})
+
+		When("material libraries are treated as single paths", func() {
+			BeforeEach(func() {
+				options := common.DefaultScanOptions()
+				options.SingleMaterialLibraryPath = true
+				scanner = obj.NewScannerWithOptions(options)
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have scanned the full paths", func() {
+				assertEvent(obj.MaterialLibraryEvent{
+					FilePath: "my materials.mtl",
+				})
+				assertEvent(obj.MaterialLibraryEvent{
+					FilePath: "other  library.mtl",
+				})
+				assertNoMoreEvents()
+			})
+		})
+	})
+
 	When("a file with all kinds of material references is scanned", func() {
 		BeforeEach(func() {
 			testFile = "valid_material_references.obj"
@@ -436,16 +710,67 @@ var _ = Describe("Scanner", func() {
 			assertEvent(obj.MaterialReferenceEvent{
 				MaterialName: "MyMaterial",
 			})
+			assertEvent(obj.MaterialReferenceEvent{
+				MaterialName: "my  material",
+			})
 			assertNoMoreEvents()
 		})
 	})
 
+	When("the positions of events are tracked", func() {
+		var sources []common.EventSource
+
+		BeforeEach(func() {
+			testFile = "valid_positions.obj"
+		})
+
+		JustBeforeEach(func() {
+			file, err := os.Open(filepath.Join("testdata", testFile))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			sources = nil
+			scanErr = scanner.(common.PositionedScanner).ScanPositioned(context.Background(), file, func(event common.Event, source common.EventSource) error {
+				sources = append(sources, source)
+				return nil
+			})
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have provided the source of each event", func() {
+			Expect(sources).To(Equal([]common.EventSource{
+				{Position: common.Position{Line: 1, Column: 1}, Command: ""},  // comment
+				{Position: common.Position{Line: 2, Column: 1}, Command: "v"}, // vertex
+				{Position: common.Position{Line: 4, Column: 1}, Command: "f"}, // face start
+				{Position: common.Position{Line: 4, Column: 3}, Command: "f"}, // reference set start
+				{Position: common.Position{Line: 4, Column: 3}, Command: "f"}, // vertex reference
+				{Position: common.Position{Line: 4, Column: 3}, Command: "f"}, // reference set end
+				{Position: common.Position{Line: 4, Column: 5}, Command: "f"}, // reference set start
+				{Position: common.Position{Line: 4, Column: 5}, Command: "f"}, // vertex reference
+				{Position: common.Position{Line: 4, Column: 5}, Command: "f"}, // reference set end
+				{Position: common.Position{Line: 5, Column: 3}, Command: "f"}, // reference set start
+				{Position: common.Position{Line: 5, Column: 3}, Command: "f"}, // vertex reference
+				{Position: common.Position{Line: 5, Column: 3}, Command: "f"}, // reference set end
+				{Position: common.Position{Line: 4, Column: 1}, Command: "f"}, // face end
+			}))
+		})
+	})
+
 	When("a file with insufficient vertex data is scanned", func() {
 		BeforeEach(func() {
 			testFile = "error_insufficient_vertex_data.obj"
 		})
 
 		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 1, Command: "v",
+		})
+
+		It("should have returned an invalid construct error", func() {
+			Expect(errors.Is(scanErr, common.ErrInvalid)).To(BeTrue())
+		})
 	})
 
 	When("a file with insufficient texture coordinate data is scanned", func() {
@@ -472,12 +797,52 @@ var _ = Describe("Scanner", func() {
 		itShouldHaveReturnedAnError()
 	})
 
+	When("a file with a missing smoothing group is scanned", func() {
+		BeforeEach(func() {
+			testFile = "error_missing_smoothing_group.obj"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("a file with corrupt smoothing group is scanned", func() {
+		BeforeEach(func() {
+			testFile = "error_corrupt_smoothing_group.obj"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
+	When("a file with negative smoothing group is scanned", func() {
+		BeforeEach(func() {
+			testFile = "error_negative_smoothing_group.obj"
+		})
+
+		itShouldHaveReturnedAnError()
+	})
+
 	When("a file with corrupt vertex is scanned", func() {
 		BeforeEach(func() {
 			testFile = "error_corrupt_vertex.obj"
 		})
 
 		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 7, Command: "v",
+		})
+	})
+
+	When("a file with corrupt vertex color is scanned", func() {
+		BeforeEach(func() {
+			testFile = "error_corrupt_vertex_color.obj"
+		})
+
+		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 19, Command: "v",
+		})
 	})
 
 	When("a file with corrupt texture coordinate is scanned", func() {
@@ -510,6 +875,10 @@ var _ = Describe("Scanner", func() {
 		})
 
 		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 3, Command: "f",
+		})
 	})
 
 	When("a file with corrupt normal reference is scanned", func() {
@@ -518,5 +887,394 @@ var _ = Describe("Scanner", func() {
 		})
 
 		itShouldHaveReturnedAnError()
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 1, Column: 3, Command: "f",
+		})
+	})
+
+	When("scanning in strict mode", func() {
+		BeforeEach(func() {
+			options := common.DefaultScanOptions()
+			options.Mode = common.ScanModeStrict
+			scanner = obj.NewScannerWithOptions(options)
+		})
+
+		When("a valid file is scanned", func() {
+			BeforeEach(func() {
+				testFile = "valid_basic.obj"
+			})
+
+			itShouldNotHaveReturnedAnError()
+		})
+
+		When("a file with unknown commands is scanned", func() {
+			BeforeEach(func() {
+				testFile = "valid_unknown_commands.obj"
+			})
+
+			itShouldHaveReturnedAParseError(common.ParseError{
+				Line: 3, Column: 1, Command: "cstype",
+			})
+		})
+
+		When("a file with comment directives is scanned", func() {
+			BeforeEach(func() {
+				testFile = "valid_comment_directives.obj"
+			})
+
+			itShouldNotHaveReturnedAnError()
+		})
+
+		When("a file with material names that contain spaces is scanned", func() {
+			BeforeEach(func() {
+				testFile = "valid_material_references.obj"
+			})
+
+			itShouldNotHaveReturnedAnError()
+		})
+
+		When("a file with excess parameters is scanned", func() {
+			BeforeEach(func() {
+				testFile = "error_excess_parameters.obj"
+			})
+
+			itShouldHaveReturnedAParseError(common.ParseError{
+				Line: 2, Column: 16, Command: "vn",
+			})
+		})
+
+		When("a file with an out of range vertex color is scanned", func() {
+			BeforeEach(func() {
+				testFile = "error_out_of_range_vertex_color.obj"
+			})
+
+			itShouldHaveReturnedAParseError(common.ParseError{
+				Line: 1, Column: 19, Command: "v",
+			})
+		})
+
+		When("a file with a zero reference is scanned", func() {
+			BeforeEach(func() {
+				testFile = "error_zero_reference.obj"
+			})
+
+			itShouldHaveReturnedAParseError(common.ParseError{
+				Line: 4, Column: 7, Command: "f",
+			})
+		})
+	})
+
+	When("a file with malformed lines is scanned", func() {
+		BeforeEach(func() {
+			testFile = "error_malformed_lines.obj"
+		})
+
+		itShouldHaveReturnedAParseError(common.ParseError{
+			Line: 2, Column: 7, Command: "v",
+		})
+
+		When("scanning in lenient mode", func() {
+			var warnings []common.Warning
+
+			BeforeEach(func() {
+				warnings = nil
+				options := common.DefaultScanOptions()
+				options.Mode = common.ScanModeLenient
+				options.WarningHandler = func(warning common.Warning) {
+					warnings = append(warnings, warning)
+				}
+				scanner = obj.NewScannerWithOptions(options)
+			})
+
+			itShouldNotHaveReturnedAnError()
+
+			It("should have skipped the malformed lines", func() {
+				assertEvent(obj.VertexEvent{
+					X: 1.0, Y: 2.0, Z: 3.0, W: 1.0,
+				})
+				assertEvent(obj.NormalEvent{
+					X: 0.0, Y: 1.0, Z: 0.0,
+				})
+				assertNoMoreEvents()
+			})
+
+			It("should have reported the malformed lines", func() {
+				Expect(warnings).To(HaveLen(3))
+				Expect(warnings[0].Line).To(Equal(2))
+				Expect(warnings[0].Column).To(Equal(7))
+				Expect(warnings[0].Command).To(Equal("v"))
+				Expect(warnings[1].Line).To(Equal(3))
+				Expect(warnings[1].Column).To(Equal(1))
+				Expect(warnings[1].Command).To(Equal("o"))
+				Expect(warnings[2].Line).To(Equal(4))
+				Expect(warnings[2].Column).To(Equal(7))
+				Expect(warnings[2].Command).To(Equal("f"))
+				Expect(warnings[2].Err).To(MatchError(ContainSubstring("invalid syntax")))
+			})
+		})
+	})
+
+	When("the handler returns an error on a reference", func() {
+		var (
+			errStubbed = errors.New("stubbed to fail")
+			failEvent  common.Event
+		)
+
+		BeforeEach(func() {
+			testFile = "valid_faces.obj"
+			handler = func(event common.Event) error {
+				if event == failEvent {
+					return errStubbed
+				}
+				return trackedHandler.Handle(event)
+			}
+		})
+
+		itShouldHaveReturnedTheHandlerError := func() {
+			GinkgoHelper()
+			It("should have returned the handler error", func() {
+				Expect(scanErr).To(Equal(errStubbed))
+			})
+		}
+
+		When("the reference is to a vertex", func() {
+			BeforeEach(func() {
+				failEvent = obj.VertexReferenceEvent{VertexIndex: 2}
+			})
+
+			itShouldHaveReturnedTheHandlerError()
+		})
+
+		When("the reference is to a texture coordinate", func() {
+			BeforeEach(func() {
+				failEvent = obj.TexCoordReferenceEvent{TexCoordIndex: 2}
+			})
+
+			itShouldHaveReturnedTheHandlerError()
+		})
+
+		When("the reference is to a normal", func() {
+			BeforeEach(func() {
+				failEvent = obj.NormalReferenceEvent{NormalIndex: 7}
+			})
+
+			itShouldHaveReturnedTheHandlerError()
+
+			It("should not have processed further events", func() {
+				Expect(trackedHandler.Events).ToNot(BeEmpty())
+				Expect(trackedHandler.Events[len(trackedHandler.Events)-1]).To(Equal(obj.VertexReferenceEvent{
+					VertexIndex: 6,
+				}))
+			})
+		})
+	})
+
+	When("the handler stops the scanning", func() {
+		BeforeEach(func() {
+			testFile = "valid_basic.obj"
+			handler = func(event common.Event) error {
+				if err := trackedHandler.Handle(event); err != nil {
+					return err
+				}
+				if _, ok := event.(obj.MaterialLibraryEvent); ok {
+					return common.ErrStop
+				}
+				return nil
+			}
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should not have scanned past the stopping event", func() {
+			assertEvent(common.CommentEvent{
+				Comment: "This is the beginning of this OBJ file.",
+			})
+			assertEvent(obj.MaterialLibraryEvent{
+				FilePath: "valid_basic.mtl",
+			})
+			assertNoMoreEvents()
+		})
+	})
+
+	When("the same scanner is used concurrently", func() {
+		scanFile := func(tracker *testutil.EventHandlerTracker) error {
+			file, err := os.Open(filepath.Join("testdata", "valid_faces.obj"))
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			return scanner.Scan(file, tracker.Handle)
+		}
+
+		BeforeEach(func() {
+			testFile = "valid_faces.obj"
+		})
+
+		It("should produce the same events for each scan", func() {
+			const scanCount = 8
+			trackers := make([]*testutil.EventHandlerTracker, scanCount)
+			errs := make([]error, scanCount)
+
+			var group sync.WaitGroup
+			for i := range trackers {
+				trackers[i] = new(testutil.EventHandlerTracker)
+				group.Add(1)
+				go func() {
+					defer group.Done()
+					errs[i] = scanFile(trackers[i])
+				}()
+			}
+			group.Wait()
+
+			for i, tracker := range trackers {
+				Expect(errs[i]).ToNot(HaveOccurred())
+				Expect(tracker.Events).To(Equal(trackedHandler.Events))
+			}
+		})
+	})
+
+	When("scanning with a context", func() {
+		var (
+			scanCtx    context.Context
+			cancelScan context.CancelFunc
+		)
+
+		scanWithContext := func() error {
+			file, err := os.Open(filepath.Join("testdata", "valid_basic.obj"))
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			options := common.DefaultScanOptions()
+			options.ContextCheckInterval = 1
+			return obj.NewScannerWithOptions(options).(common.ContextScanner).ScanContext(scanCtx, file, handler)
+		}
+
+		BeforeEach(func() {
+			testFile = "valid_basic.obj"
+			scanCtx, cancelScan = context.WithCancel(context.Background())
+			DeferCleanup(func() {
+				cancelScan()
+			})
+		})
+
+		It("should scan the whole file", func() {
+			trackedHandler.Events = nil
+			Expect(scanWithContext()).To(Succeed())
+			Expect(trackedHandler.Events).ToNot(BeEmpty())
+		})
+
+		It("should not scan when the context is cancelled", func() {
+			trackedHandler.Events = nil
+			cancelScan()
+			Expect(scanWithContext()).To(MatchError(context.Canceled))
+			Expect(trackedHandler.Events).To(BeEmpty())
+		})
+
+		It("should stop scanning when the context is cancelled", func() {
+			trackedHandler.Events = nil
+			handler = func(event common.Event) error {
+				cancelScan()
+				return trackedHandler.Handle(event)
+			}
+			Expect(scanWithContext()).To(MatchError(context.Canceled))
+			Expect(trackedHandler.Events).To(Equal([]common.Event{
+				common.CommentEvent{
+					Comment: "This is the beginning of this OBJ file.",
+				},
+			}))
+		})
+	})
+
+	When("scanning with a progress handler", func() {
+		var reports []common.Progress
+
+		BeforeEach(func() {
+			reports = nil
+			testFile = "valid_basic.obj"
+			options := common.DefaultScanOptions()
+			options.ProgressInterval = -1
+			options.ProgressHandler = func(progress common.Progress) {
+				reports = append(reports, progress)
+			}
+			scanner = obj.NewScannerWithOptions(options)
+		})
+
+		itShouldNotHaveReturnedAnError()
+
+		It("should have reported the progress of each line", func() {
+			Expect(reports).To(HaveLen(21))
+			Expect(reports[0].Lines).To(Equal(1))
+			Expect(reports[0].Elements).To(Equal(0))
+			Expect(reports[0].BytesRead).To(Equal(int64(42)))
+			Expect(reports[20]).To(Equal(common.Progress{
+				BytesRead:  272,
+				TotalBytes: 272,
+				Lines:      21,
+				Elements:   15,
+			}))
+		})
+
+		When("the progress is throttled", func() {
+			BeforeEach(func() {
+				options := common.DefaultScanOptions()
+				options.ProgressInterval = time.Hour
+				options.ProgressHandler = func(progress common.Progress) {
+					reports = append(reports, progress)
+				}
+				scanner = obj.NewScannerWithOptions(options)
+			})
+
+			It("should have reported only the final progress", func() {
+				Expect(reports).To(HaveLen(1))
+				Expect(reports[0].Lines).To(Equal(21))
+			})
+		})
+
+		When("the progress interval is not specified", func() {
+			BeforeEach(func() {
+				options := common.DefaultScanOptions()
+				options.ProgressInterval = 0
+				options.ProgressHandler = func(progress common.Progress) {
+					reports = append(reports, progress)
+				}
+				scanner = obj.NewScannerWithOptions(options)
+			})
+
+			It("should have throttled the progress with the default interval", func() {
+				Expect(reports).To(HaveLen(1))
+				Expect(reports[0].Lines).To(Equal(21))
+			})
+		})
+
+		When("the size of the resource cannot be determined", func() {
+			It("should have reported an unknown size", func() {
+				reports = nil
+				file, err := os.Open(filepath.Join("testdata", testFile))
+				Expect(err).ToNot(HaveOccurred())
+				defer file.Close()
+
+				Expect(scanner.Scan(io.MultiReader(file), handler)).To(Succeed())
+				Expect(reports).ToNot(BeEmpty())
+				Expect(reports[len(reports)-1].BytesRead).To(Equal(int64(272)))
+				Expect(reports[len(reports)-1].TotalBytes).To(BeZero())
+			})
+		})
+
+		When("the size of the resource is specified", func() {
+			BeforeEach(func() {
+				options := common.DefaultScanOptions()
+				options.TotalBytes = 1024
+				options.ProgressHandler = func(progress common.Progress) {
+					reports = append(reports, progress)
+				}
+				scanner = obj.NewScannerWithOptions(options)
+			})
+
+			It("should have reported the specified size", func() {
+				Expect(reports).ToNot(BeEmpty())
+				Expect(reports[len(reports)-1].TotalBytes).To(Equal(int64(1024)))
+			})
+		})
 	})
 })